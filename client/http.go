@@ -6,55 +6,101 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
 	"strings"
 	"time"
 )
 
+// HTTPClient represents the backend API client, the times of its responses are rendered in TimeZone
 type HTTPClient struct {
 	client     *http.Client
 	BackendURL string
+	TimeZone   TimeZone
 }
 
-type reminderBody struct {
-	ID          string        `json:"id"`
+// ReminderBody represents the reminder fields sent to the backend API,
+// the reminder is due either after Duration or at DueAt and optionally repeats by the Repeat RRULE,
+// a non-nil Actions replaces the custom notification buttons of the reminder,
+// a non-nil QuietHours replaces its quiet-hours windows and a non-nil Tags its tags,
+// TimeZone is the IANA time zone its due time and recurrence are evaluated in and Priority is low, normal, high or urgent
+type ReminderBody struct {
 	Title       string        `json:"title"`
 	Message     string        `json:"message"`
 	Duration    time.Duration `json:"duration"`
+	DueAt       *time.Time    `json:"due_at,omitempty"`
 	RetryPeriod time.Duration `json:"retry_period"`
+	Repeat      string        `json:"repeat,omitempty"`
+	Actions     *[]ActionBody `json:"actions,omitempty"`
+	Channel     string        `json:"channel,omitempty"`
+	QuietHours  *[]string     `json:"quiet_hours,omitempty"`
+	TimeZone    string        `json:"time_zone,omitempty"`
+	Tags        *[]string     `json:"tags,omitempty"`
+	Priority    string        `json:"priority,omitempty"`
 }
 
-func NewHTTPClient(url string) HTTPClient {
+// ActionBody represents a custom notification button and the effect clicking it has on the reminder
+type ActionBody struct {
+	Label  string        `json:"label"`
+	Effect string        `json:"effect"`
+	Snooze time.Duration `json:"snooze,omitempty"`
+}
+
+// SnoozeBody represents the snooze request body,
+// the reminder is notified again either after Duration or at Until
+type SnoozeBody struct {
+	Duration time.Duration `json:"duration,omitempty"`
+	Until    *time.Time    `json:"until,omitempty"`
+}
+
+// ReopenBody represents the reopen request body,
+// the reminder keeps its due time unless a new Duration or DueAt is provided
+type ReopenBody struct {
+	Duration time.Duration `json:"duration,omitempty"`
+	DueAt    *time.Time    `json:"due_at,omitempty"`
+}
+
+// DNDBody represents the do-not-disturb request body,
+// an enabled do-not-disturb lasts for Duration, until Until or until it is disabled
+type DNDBody struct {
+	Enabled  bool          `json:"enabled"`
+	Duration time.Duration `json:"duration,omitempty"`
+	Until    *time.Time    `json:"until,omitempty"`
+}
+
+func NewHTTPClient(url string, timeZone TimeZone) HTTPClient {
 	return HTTPClient{
 		BackendURL: url,
+		TimeZone:   timeZone,
 		client:     &http.Client{},
 	}
 }
 
-func (c HTTPClient) Create(title, message string, duration, retryPeriod time.Duration) ([]byte, error) {
-	requestBody := reminderBody{
-		Title:       title,
-		Message:     message,
-		Duration:    duration,
-		RetryPeriod: retryPeriod,
-	}
+func (c HTTPClient) Create(body ReminderBody) ([]byte, error) {
+	return c.apiCall(http.MethodPost, "/reminders", &body, http.StatusCreated)
+}
 
-	return c.apiCall(http.MethodPost, "/reminders", &requestBody, http.StatusCreated)
+func (c HTTPClient) Edit(id string, body ReminderBody) ([]byte, error) {
+	return c.apiCall(http.MethodPatch, "/reminders/"+id, &body, http.StatusOK)
 }
 
-func (c HTTPClient) Edit(id, title, message string, duration, retryPeriod time.Duration) ([]byte, error) {
-	requestBody := reminderBody{
-		ID:          id,
-		Title:       title,
-		Message:     message,
-		Duration:    duration,
-		RetryPeriod: retryPeriod,
+func (c HTTPClient) Fetch(ids []string, query url.Values) ([]byte, error) {
+	path := "/reminders/" + strings.Join(ids, ",")
+	if len(query) > 0 {
+		path += "?" + query.Encode()
 	}
-	return c.apiCall(http.MethodPatch, "/reminders/"+id, &requestBody, http.StatusOK)
+	return c.apiCall(http.MethodGet, path, nil, http.StatusOK)
 }
 
-func (c HTTPClient) Fetch(ids []string) ([]byte, error) {
-	idsStr := strings.Join(ids, ",")
-	return c.apiCall(http.MethodGet, "/reminders/"+idsStr, nil, http.StatusOK)
+func (c HTTPClient) List(query url.Values) ([]byte, error) {
+	path := "/reminders"
+	if len(query) > 0 {
+		path += "?" + query.Encode()
+	}
+	return c.apiCall(http.MethodGet, path, nil, http.StatusOK)
+}
+
+func (c HTTPClient) Search(query url.Values) ([]byte, error) {
+	return c.apiCall(http.MethodGet, "/reminders/search?"+query.Encode(), nil, http.StatusOK)
 }
 
 func (c HTTPClient) Delete(ids []string) error {
@@ -63,6 +109,42 @@ func (c HTTPClient) Delete(ids []string) error {
 	return err
 }
 
+func (c HTTPClient) Tags() ([]byte, error) {
+	return c.apiCall(http.MethodGet, "/tags", nil, http.StatusOK)
+}
+
+func (c HTTPClient) Deliveries(status string) ([]byte, error) {
+	path := "/deliveries"
+	if status != "" {
+		path += "?" + url.Values{"status": {status}}.Encode()
+	}
+	return c.apiCall(http.MethodGet, path, nil, http.StatusOK)
+}
+
+func (c HTTPClient) Cancel(id string) ([]byte, error) {
+	return c.apiCall(http.MethodPost, "/reminders/"+id+"/cancel", nil, http.StatusOK)
+}
+
+func (c HTTPClient) Complete(id string) ([]byte, error) {
+	return c.apiCall(http.MethodPost, "/reminders/"+id+"/complete", nil, http.StatusOK)
+}
+
+func (c HTTPClient) Snooze(id string, body SnoozeBody) ([]byte, error) {
+	return c.apiCall(http.MethodPost, "/reminders/"+id+"/snooze", &body, http.StatusOK)
+}
+
+func (c HTTPClient) Reopen(id string, body ReopenBody) ([]byte, error) {
+	return c.apiCall(http.MethodPost, "/reminders/"+id+"/reopen", &body, http.StatusOK)
+}
+
+func (c HTTPClient) SetDND(body DNDBody) ([]byte, error) {
+	return c.apiCall(http.MethodPost, "/dnd", &body, http.StatusOK)
+}
+
+func (c HTTPClient) DND() ([]byte, error) {
+	return c.apiCall(http.MethodGet, "/dnd", nil, http.StatusOK)
+}
+
 func (c HTTPClient) Healthy(host string) bool {
 	res, err := http.Get(host + "/health")
 	if err != nil || res.StatusCode != http.StatusOK {
@@ -121,7 +203,7 @@ func (c HTTPClient) readResBody(b io.Reader) (string, error) {
 
 	var buf bytes.Buffer
 
-	if err := json.Indent(&buf, data, "", "\t"); err != nil {
+	if err := json.Indent(&buf, c.TimeZone.render(data), "", "\t"); err != nil {
 		return "", wrapError("could not indent json", err)
 	}
 
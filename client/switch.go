@@ -3,7 +3,9 @@ package client
 import (
 	"flag"
 	"fmt"
+	"net/url"
 	"os"
+	"strconv"
 	"strings"
 	"time"
 )
@@ -19,32 +21,121 @@ func (ids *idsFlag) Set(v string) error {
 	return nil
 }
 
+// last retrieves the last ID of the commands acting on a single reminder, it fails when --id is missing
+func (ids idsFlag) last(cmdName string) (string, error) {
+	if len(ids) == 0 || ids[len(ids)-1] == "" {
+		return "", fmt.Errorf("%s requires the --id of a reminder", cmdName)
+	}
+	return ids[len(ids)-1], nil
+}
+
+// actionsFlag collects the repeated --action flags formatted as label=effect[:snooze],
+// the "none" value removes all the actions of an edited reminder
+type actionsFlag struct {
+	set     bool
+	actions []ActionBody
+}
+
+func (a *actionsFlag) String() string {
+	var actions []string
+	for _, action := range a.actions {
+		actions = append(actions, action.Label+"="+action.Effect)
+	}
+	return strings.Join(actions, ",")
+}
+
+func (a *actionsFlag) Set(v string) error {
+	a.set = true
+	if strings.EqualFold(v, "none") {
+		a.actions = []ActionBody{}
+		return nil
+	}
+	label, effect, ok := strings.Cut(v, "=")
+	if !ok || label == "" {
+		return fmt.Errorf("action must be formatted as label=effect[:snooze]")
+	}
+	action := ActionBody{Label: label, Effect: effect}
+	if effect, snooze, ok := strings.Cut(effect, ":"); ok {
+		d, err := time.ParseDuration(snooze)
+		if err != nil {
+			return fmt.Errorf("invalid snooze duration '%s'", snooze)
+		}
+		action.Effect, action.Snooze = effect, d
+	}
+	a.actions = append(a.actions, action)
+	return nil
+}
+
+// listFlag collects the values of a repeated flag like --quiet or --tag,
+// the "none" value removes all the values of an edited reminder
+type listFlag struct {
+	set    bool
+	values []string
+}
+
+func (l *listFlag) String() string {
+	return strings.Join(l.values, ";")
+}
+
+func (l *listFlag) Set(v string) error {
+	l.set = true
+	if strings.EqualFold(v, "none") {
+		l.values = []string{}
+		return nil
+	}
+	l.values = append(l.values, v)
+	return nil
+}
+
 type BackendHTTPClient interface {
-	Create(title, message string, duration, retryPeriod time.Duration) ([]byte, error)
-	Edit(id, title, message string, duration, retryPeriod time.Duration) ([]byte, error)
-	Fetch(ids []string) ([]byte, error)
+	Create(body ReminderBody) ([]byte, error)
+	Edit(id string, body ReminderBody) ([]byte, error)
+	Fetch(ids []string, query url.Values) ([]byte, error)
+	List(query url.Values) ([]byte, error)
+	Search(query url.Values) ([]byte, error)
 	Delete(ids []string) error
+	Cancel(id string) ([]byte, error)
+	Complete(id string) ([]byte, error)
+	Snooze(id string, body SnoozeBody) ([]byte, error)
+	Reopen(id string, body ReopenBody) ([]byte, error)
+	Tags() ([]byte, error)
+	Deliveries(status string) ([]byte, error)
+	SetDND(body DNDBody) ([]byte, error)
+	DND() ([]byte, error)
 	Healthy(host string) bool
 }
 
+// Switch represents the client commands, absolute times without a UTC offset are parsed in timeZone
+// and new reminders use it unless they have their own
 type Switch struct {
 	client        BackendHTTPClient
 	backendAPIURL string
+	timeZone      TimeZone
 	commands      map[string]func(string) error
 }
 
-func NewSwitch(url string) Switch {
-	httpClient := NewHTTPClient(url)
+func NewSwitch(url string, timeZone TimeZone) Switch {
+	httpClient := NewHTTPClient(url, timeZone)
 	s := Switch{
 		client:        httpClient,
 		backendAPIURL: url,
+		timeZone:      timeZone,
 	}
 	s.commands = map[string]func(string) error{
-		"create": s.create,
-		"edit":   s.edit,
-		"fetch":  s.fetch,
-		"delete": s.delete,
-		"health": s.health,
+		"create":     s.create,
+		"edit":       s.edit,
+		"fetch":      s.fetch,
+		"list":       s.list,
+		"search":     s.search,
+		"delete":     s.delete,
+		"cancel":     s.cancel,
+		"complete":   s.complete,
+		"snooze":     s.snooze,
+		"reopen":     s.reopen,
+		"tags":       s.tags,
+		"deliveries": s.deliveries,
+		"dnd":        s.dnd,
+		"health":     s.health,
 	}
 	return s
 }
@@ -68,7 +159,7 @@ func (s Switch) Help() {
 
 func (s Switch) create(cmdName string) error {
 	createCmd := flag.NewFlagSet(cmdName, flag.ExitOnError)
-	title, message, duration, retryPeriod := s.reminderFlags(createCmd)
+	flags := s.reminderFlags(createCmd)
 
 	if err := s.checkArgs(4); err != nil {
 		return err
@@ -78,7 +169,19 @@ func (s Switch) create(cmdName string) error {
 		return err
 	}
 
-	res, err := s.client.Create(*title, *message, *duration, *retryPeriod)
+	body, err := flags.body()
+	if err != nil {
+		return err
+	}
+	if body.TimeZone == "" {
+		body.TimeZone = s.timeZone.Name
+	}
+	if flags.dryRun {
+		s.timeZone.dryRun("reminder", body.DueAt, body.Duration)
+		return nil
+	}
+
+	res, err := s.client.Create(body)
 	if err != nil {
 		return wrapError("could not create reminder", err)
 	}
@@ -91,7 +194,7 @@ func (s Switch) edit(cmdName string) error {
 	ids := idsFlag{}
 	editCmd := flag.NewFlagSet(cmdName, flag.ExitOnError)
 	editCmd.Var(&ids, "id", "ID (int) of the reminder to edit")
-	title, message, duration, retryPeriod := s.reminderFlags(editCmd)
+	flags := s.reminderFlags(editCmd)
 
 	if err := s.checkArgs(2); err != nil {
 		return err
@@ -101,8 +204,20 @@ func (s Switch) edit(cmdName string) error {
 		return err
 	}
 
-	lastID := ids[len(ids)-1]
-	res, err := s.client.Edit(lastID, *title, *message, *duration, *retryPeriod)
+	body, err := flags.body()
+	if err != nil {
+		return err
+	}
+	if flags.dryRun {
+		s.timeZone.dryRun("reminder", body.DueAt, body.Duration)
+		return nil
+	}
+
+	lastID, err := ids.last(cmdName)
+	if err != nil {
+		return err
+	}
+	res, err := s.client.Edit(lastID, body)
 	if err != nil {
 		return wrapError("could not edit reminder", err)
 	}
@@ -114,7 +229,10 @@ func (s Switch) edit(cmdName string) error {
 func (s Switch) fetch(cmdName string) error {
 	ids := idsFlag{}
 	fetchCmd := flag.NewFlagSet(cmdName, flag.ExitOnError)
+	var tag, tagMatch string
 	fetchCmd.Var(&ids, "id", "List of reminder IDs (int) to fetch")
+	fetchCmd.StringVar(&tag, "tag", "", "Comma separated tags the fetched reminders are filtered by")
+	fetchCmd.StringVar(&tagMatch, "tag_match", "", "Reminders having any (default), all or none of the tags")
 
 	if err := s.checkArgs(1); err != nil {
 		return err
@@ -124,7 +242,15 @@ func (s Switch) fetch(cmdName string) error {
 		return err
 	}
 
-	res, err := s.client.Fetch(ids)
+	query := url.Values{}
+	if tag != "" {
+		query.Set("tag", tag)
+	}
+	if tagMatch != "" {
+		query.Set("tag_match", tagMatch)
+	}
+
+	res, err := s.client.Fetch(ids, query)
 	if err != nil {
 		return wrapError("could not fetch reminder(s)", err)
 	}
@@ -133,6 +259,86 @@ func (s Switch) fetch(cmdName string) error {
 	return nil
 }
 
+func (s Switch) list(cmdName string) error {
+	listCmd := flag.NewFlagSet(cmdName, flag.ExitOnError)
+	params := []struct {
+		name  string
+		usage string
+	}{
+		{"status", "Reminder status: pending, firing, snoozed, completed, cancelled, failed, uncompleted or overdue"},
+		{"title", "Substring of the reminder title"},
+		{"missed", "Only the reminders which came due while the server was down (true) or the others (false)"},
+		{"tag", "Comma separated tags the reminders are filtered by"},
+		{"tag_match", "Reminders having any (default), all or none of the tags"},
+		{"priority", "Comma separated priorities: low, normal, high or urgent"},
+		{"created_after", "Created after (RFC3339 time)"},
+		{"created_before", "Created before (RFC3339 time)"},
+		{"modified_after", "Modified after (RFC3339 time)"},
+		{"modified_before", "Modified before (RFC3339 time)"},
+		{"due_after", "Due after (RFC3339 time)"},
+		{"due_before", "Due before (RFC3339 time)"},
+		{"sort", "Sort field: id, title, created_at, modified_at, due_at or priority"},
+		{"order", "Sort order: asc or desc"},
+		{"limit", "Maximum number of reminders per page"},
+		{"next", "Next page token"},
+	}
+	values := make([]*string, len(params))
+	for i, p := range params {
+		values[i] = listCmd.String(p.name, "", p.usage)
+	}
+
+	if err := s.parseCmd(listCmd); err != nil {
+		return err
+	}
+
+	query := url.Values{}
+	for i, p := range params {
+		if *values[i] != "" {
+			query.Set(p.name, *values[i])
+		}
+	}
+
+	res, err := s.client.List(query)
+	if err != nil {
+		return wrapError("could not list reminders", err)
+	}
+
+	fmt.Println("reminders listed successfully:", string(res))
+	return nil
+}
+
+func (s Switch) search(cmdName string) error {
+	var q string
+	var limit int
+	searchCmd := flag.NewFlagSet(cmdName, flag.ExitOnError)
+	searchCmd.StringVar(&q, "q", "", "Search query, terms ending with * match as prefixes and quoted terms as phrases (the remaining args are used when omitted)")
+	searchCmd.IntVar(&limit, "limit", 0, "Maximum number of matches")
+
+	if err := s.checkArgs(1); err != nil {
+		return err
+	}
+
+	if err := s.parseCmd(searchCmd); err != nil {
+		return err
+	}
+	if q == "" {
+		q = strings.Join(searchCmd.Args(), " ")
+	}
+
+	query := url.Values{"q": {q}}
+	if limit != 0 {
+		query.Set("limit", strconv.Itoa(limit))
+	}
+
+	res, err := s.client.Search(query)
+	if err != nil {
+		return wrapError("could not search reminders", err)
+	}
+
+	fmt.Println("reminder(s) found:", string(res))
+	return nil
+}
+
 func (s Switch) delete(cmdName string) error {
 	ids := idsFlag{}
 	deleteCmd := flag.NewFlagSet(cmdName, flag.ExitOnError)
@@ -155,6 +361,226 @@ func (s Switch) delete(cmdName string) error {
 	return nil
 }
 
+func (s Switch) deliveries(cmdName string) error {
+	deliveriesCmd := flag.NewFlagSet(cmdName, flag.ExitOnError)
+	status := deliveriesCmd.String("status", "", "Delivery status: queued, sending, awaiting or dead")
+
+	if err := s.parseCmd(deliveriesCmd); err != nil {
+		return err
+	}
+
+	res, err := s.client.Deliveries(*status)
+	if err != nil {
+		return wrapError("could not list deliveries", err)
+	}
+
+	fmt.Println("deliveries listed successfully:", string(res))
+	return nil
+}
+
+func (s Switch) tags(cmdName string) error {
+	tagsCmd := flag.NewFlagSet(cmdName, flag.ExitOnError)
+
+	if err := s.parseCmd(tagsCmd); err != nil {
+		return err
+	}
+
+	res, err := s.client.Tags()
+	if err != nil {
+		return wrapError("could not list tags", err)
+	}
+
+	fmt.Println("tags listed successfully:", string(res))
+	return nil
+}
+
+func (s Switch) cancel(cmdName string) error {
+	ids := idsFlag{}
+	cancelCmd := flag.NewFlagSet(cmdName, flag.ExitOnError)
+	cancelCmd.Var(&ids, "id", "ID (int) of the reminder to cancel")
+
+	if err := s.checkArgs(1); err != nil {
+		return err
+	}
+
+	if err := s.parseCmd(cancelCmd); err != nil {
+		return err
+	}
+
+	lastID, err := ids.last(cmdName)
+	if err != nil {
+		return err
+	}
+	res, err := s.client.Cancel(lastID)
+	if err != nil {
+		return wrapError("could not cancel reminder", err)
+	}
+
+	fmt.Println("reminder cancelled successfully:", string(res))
+	return nil
+}
+
+func (s Switch) complete(cmdName string) error {
+	ids := idsFlag{}
+	completeCmd := flag.NewFlagSet(cmdName, flag.ExitOnError)
+	completeCmd.Var(&ids, "id", "ID (int) of the reminder to complete")
+
+	if err := s.checkArgs(1); err != nil {
+		return err
+	}
+
+	if err := s.parseCmd(completeCmd); err != nil {
+		return err
+	}
+
+	lastID, err := ids.last(cmdName)
+	if err != nil {
+		return err
+	}
+	res, err := s.client.Complete(lastID)
+	if err != nil {
+		return wrapError("could not complete reminder", err)
+	}
+
+	fmt.Println("reminder completed successfully:", string(res))
+	return nil
+}
+
+func (s Switch) snooze(cmdName string) error {
+	ids := idsFlag{}
+	var duration time.Duration
+	var until string
+	var dryRun bool
+	snoozeCmd := flag.NewFlagSet(cmdName, flag.ExitOnError)
+	snoozeCmd.Var(&ids, "id", "ID (int) of the reminder to snooze")
+	snoozeCmd.DurationVar(&duration, "duration", 0, "Snooze time relative to now")
+	snoozeCmd.DurationVar(&duration, "d", 0, "Snooze time relative to now")
+	snoozeCmd.StringVar(&until, "until", "", "Snooze until an absolute time (RFC3339, YYYY-MM-DD HH:MM or e.g. \"tomorrow at 9\", \"in 2 weeks\")")
+	snoozeCmd.StringVar(&until, "u", "", "Snooze until an absolute time (RFC3339, YYYY-MM-DD HH:MM or e.g. \"tomorrow at 9\", \"in 2 weeks\")")
+	snoozeCmd.BoolVar(&dryRun, "dry-run", false, "Print the resolved snooze time without snoozing")
+
+	if err := s.checkArgs(2); err != nil {
+		return err
+	}
+
+	if err := s.parseCmd(snoozeCmd); err != nil {
+		return err
+	}
+
+	body := SnoozeBody{Duration: duration}
+	if until != "" {
+		t, err := s.timeZone.parseTime(until)
+		if err != nil {
+			return wrapError("invalid --until time", err)
+		}
+		body.Until = &t
+	}
+	if dryRun {
+		s.timeZone.dryRun("reminder", body.Until, body.Duration)
+		return nil
+	}
+
+	lastID, err := ids.last(cmdName)
+	if err != nil {
+		return err
+	}
+	res, err := s.client.Snooze(lastID, body)
+	if err != nil {
+		return wrapError("could not snooze reminder", err)
+	}
+
+	fmt.Println("reminder snoozed successfully:", string(res))
+	return nil
+}
+
+func (s Switch) reopen(cmdName string) error {
+	ids := idsFlag{}
+	var duration time.Duration
+	var at string
+	reopenCmd := flag.NewFlagSet(cmdName, flag.ExitOnError)
+	reopenCmd.Var(&ids, "id", "ID (int) of the reminder to reopen")
+	reopenCmd.DurationVar(&duration, "duration", 0, "New reminder time relative to now")
+	reopenCmd.DurationVar(&duration, "d", 0, "New reminder time relative to now")
+	reopenCmd.StringVar(&at, "at", "", "New reminder absolute time (RFC3339, YYYY-MM-DD HH:MM or e.g. \"tomorrow at 9\", \"in 2 weeks\")")
+	reopenCmd.StringVar(&at, "a", "", "New reminder absolute time (RFC3339, YYYY-MM-DD HH:MM or e.g. \"tomorrow at 9\", \"in 2 weeks\")")
+
+	if err := s.checkArgs(1); err != nil {
+		return err
+	}
+
+	if err := s.parseCmd(reopenCmd); err != nil {
+		return err
+	}
+
+	body := ReopenBody{Duration: duration}
+	if at != "" {
+		t, err := s.timeZone.parseTime(at)
+		if err != nil {
+			return wrapError("invalid --at time", err)
+		}
+		body.DueAt = &t
+	}
+
+	lastID, err := ids.last(cmdName)
+	if err != nil {
+		return err
+	}
+	res, err := s.client.Reopen(lastID, body)
+	if err != nil {
+		return wrapError("could not reopen reminder", err)
+	}
+
+	fmt.Println("reminder reopened successfully:", string(res))
+	return nil
+}
+
+func (s Switch) dnd(cmdName string) error {
+	var duration time.Duration
+	var until string
+	dndCmd := flag.NewFlagSet(cmdName+" on|off|status", flag.ExitOnError)
+	dndCmd.DurationVar(&duration, "for", 0, "Do not disturb for a time relative to now")
+	dndCmd.StringVar(&until, "until", "", "Do not disturb until an absolute time (RFC3339, YYYY-MM-DD HH:MM or e.g. \"tomorrow at 9\", \"in 2 weeks\")")
+
+	if err := s.checkArgs(1); err != nil {
+		return err
+	}
+	mode := os.Args[2]
+	if mode == "--help" {
+		dndCmd.Usage()
+		return nil
+	}
+	if err := dndCmd.Parse(os.Args[3:]); err != nil {
+		return wrapError("could not parse '"+cmdName+"' command flags", err)
+	}
+
+	var res []byte
+	var err error
+	switch mode {
+	case "on":
+		body := DNDBody{Enabled: true, Duration: duration}
+		if until != "" {
+			t, err := s.timeZone.parseTime(until)
+			if err != nil {
+				return wrapError("invalid --until time", err)
+			}
+			body.Until = &t
+		}
+		res, err = s.client.SetDND(body)
+	case "off":
+		res, err = s.client.SetDND(DNDBody{Enabled: false})
+	case "status":
+		res, err = s.client.DND()
+	default:
+		return fmt.Errorf("invalid dnd mode '%s', expected on, off or status", mode)
+	}
+	if err != nil {
+		return wrapError("could not "+cmdName+" "+mode, err)
+	}
+
+	fmt.Println("do not disturb:", string(res))
+	return nil
+}
+
 func (s Switch) health(cmdName string) error {
 	var host string
 	healthCmd := flag.NewFlagSet(cmdName, flag.ExitOnError)
@@ -173,19 +599,87 @@ func (s Switch) health(cmdName string) error {
 	return nil
 }
 
-func (s Switch) reminderFlags(f *flag.FlagSet) (*string, *string, *time.Duration, *time.Duration) {
-	title, message, duration, retryPeriod := "", "", time.Duration(0), time.Duration(0)
+// reminderFlags represents the flags shared by the create & edit commands,
+// absolute times are parsed in the --tz time zone or else in the client time zone
+type reminderFlags struct {
+	title       string
+	message     string
+	duration    time.Duration
+	at          string
+	retryPeriod time.Duration
+	repeat      string
+	actions     actionsFlag
+	channel     string
+	quiet       listFlag
+	tags        listFlag
+	priority    string
+	tz          string
+	dryRun      bool
+	timeZone    TimeZone
+}
 
-	f.StringVar(&title, "title", "", "Reminder title")
-	f.StringVar(&title, "t", "", "Reminder title")
-	f.StringVar(&message, "message", "", "Reminder message")
-	f.StringVar(&message, "m", "", "Reminder message")
-	f.DurationVar(&duration, "duration", 0, "Reminder time")
-	f.DurationVar(&duration, "d", 0, "Reminder time")
-	f.DurationVar(&retryPeriod, "retry_period", 0, "Reminder retry period")
-	f.DurationVar(&retryPeriod, "r", 0, "Reminder retry period")
+// body converts the parsed flags to the backend API request body
+func (f *reminderFlags) body() (ReminderBody, error) {
+	body := ReminderBody{
+		Title:       f.title,
+		Message:     f.message,
+		Duration:    f.duration,
+		RetryPeriod: f.retryPeriod,
+		Repeat:      f.repeat,
+		Channel:     f.channel,
+		Priority:    f.priority,
+	}
+	if f.actions.set {
+		body.Actions = &f.actions.actions
+	}
+	if f.quiet.set {
+		body.QuietHours = &f.quiet.values
+	}
+	if f.tags.set {
+		body.Tags = &f.tags.values
+	}
+	timeZone := f.timeZone
+	if f.tz != "" {
+		tz, err := LoadTimeZone(f.tz)
+		if err != nil {
+			return body, wrapError("invalid --tz time zone", err)
+		}
+		timeZone, body.TimeZone = tz, tz.Name
+	}
+	if f.at != "" {
+		dueAt, err := timeZone.parseTime(f.at)
+		if err != nil {
+			return body, wrapError("invalid --at time", err)
+		}
+		body.DueAt = &dueAt
+	}
+	return body, nil
+}
 
-	return &title, &message, &duration, &retryPeriod
+func (s Switch) reminderFlags(f *flag.FlagSet) *reminderFlags {
+	flags := &reminderFlags{timeZone: s.timeZone}
+
+	f.StringVar(&flags.title, "title", "", "Reminder title")
+	f.StringVar(&flags.title, "t", "", "Reminder title")
+	f.StringVar(&flags.message, "message", "", "Reminder message")
+	f.StringVar(&flags.message, "m", "", "Reminder message")
+	f.DurationVar(&flags.duration, "duration", 0, "Reminder time relative to now")
+	f.DurationVar(&flags.duration, "d", 0, "Reminder time relative to now")
+	f.StringVar(&flags.at, "at", "", "Reminder absolute time (RFC3339, YYYY-MM-DD HH:MM or e.g. \"tomorrow at 9\", \"in 2 weeks\")")
+	f.StringVar(&flags.at, "a", "", "Reminder absolute time (RFC3339, YYYY-MM-DD HH:MM or e.g. \"tomorrow at 9\", \"in 2 weeks\")")
+	f.DurationVar(&flags.retryPeriod, "retry_period", 0, "Reminder retry period")
+	f.DurationVar(&flags.retryPeriod, "r", 0, "Reminder retry period")
+	f.StringVar(&flags.repeat, "repeat", "", "Reminder recurrence RRULE (e.g. FREQ=WEEKLY;BYDAY=MO,FR) or daily, weekly, monthly, yearly, none")
+	f.StringVar(&flags.channel, "channel", "", "Notification channel configured on the server, default resets it")
+	f.Var(&flags.quiet, "quiet", "Quiet hours holding the notifications as \"[days] HH:MM-HH:MM [time zone]\", repeatable, none removes them")
+	f.StringVar(&flags.tz, "tz", "", "Reminder IANA time zone (e.g. Europe/Berlin), Local uses the server time zone")
+	f.BoolVar(&flags.dryRun, "dry-run", false, "Print the resolved due time without sending the reminder")
+	f.StringVar(&flags.priority, "priority", "", "Reminder priority: low, normal (default), high or urgent")
+	f.StringVar(&flags.priority, "p", "", "Reminder priority: low, normal (default), high or urgent")
+	f.Var(&flags.tags, "tag", "Reminder tag (e.g. work, oncall), repeatable, none removes them")
+	f.Var(&flags.actions, "action", "Notification button as label=effect[:snooze] with effect complete, snooze, retry or cancel, repeatable, none removes the buttons")
+
+	return flags
 }
 
 func (s Switch) parseCmd(cmd *flag.FlagSet) error {
@@ -0,0 +1,22 @@
+package client
+
+import "testing"
+
+func TestIdsFlagLast(t *testing.T) {
+	var ids idsFlag
+	if _, err := ids.last("cancel"); err == nil {
+		t.Fatal("got an ID without --id, want an error")
+	}
+	if err := ids.Set(""); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ids.last("cancel"); err == nil {
+		t.Fatal("got an ID for an empty --id, want an error")
+	}
+	if err := ids.Set("3,7"); err != nil {
+		t.Fatal(err)
+	}
+	if id, err := ids.last("cancel"); err != nil || id != "7" {
+		t.Fatalf("got ID %q (%v), want the last one", id, err)
+	}
+}
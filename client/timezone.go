@@ -0,0 +1,170 @@
+package client
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+)
+
+// TimeZoneEnv names the environment variable overriding the client time zone
+const TimeZoneEnv = "REMINDERS_TZ"
+
+// localTimeLayouts lists the accepted layouts of absolute times without a UTC offset
+var localTimeLayouts = []string{
+	"2006-01-02T15:04:05",
+	"2006-01-02T15:04",
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+}
+
+// dryRunLayout formats the times resolved by --dry-run
+const dryRunLayout = "Mon 2006-01-02 15:04 MST"
+
+// timeFields lists the fields of the backend responses holding a time
+var timeFields = map[string]bool{
+	"due_at":          true,
+	"fired_at":        true,
+	"completed_at":    true,
+	"created_at":      true,
+	"modified_at":     true,
+	"current":         true,
+	"next_attempt_at": true,
+	"sent_at":         true,
+	"expires_at":      true,
+	"last_attempt_at": true,
+	"opened_at":       true,
+	"until":           true,
+	"quiet_until":     true,
+}
+
+// jsonScope represents an object or array being rendered, n counts its keys and values so far
+type jsonScope struct {
+	object bool
+	n      int
+	key    string
+}
+
+// TimeZone represents the time zone the client parses and renders times in,
+// Name is the IANA name sent to the backend and is empty when it is unknown
+type TimeZone struct {
+	Name     string
+	Location *time.Location
+}
+
+// LoadTimeZone loads a time zone by its IANA name
+func LoadTimeZone(name string) (TimeZone, error) {
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return TimeZone{}, fmt.Errorf("unknown time zone '%s'", name)
+	}
+	return TimeZone{Name: name, Location: loc}, nil
+}
+
+// DefaultTimeZone retrieves the client time zone from the REMINDERS_TZ or TZ environment variables
+// or else from the system time zone
+func DefaultTimeZone() (TimeZone, error) {
+	if name := os.Getenv(TimeZoneEnv); name != "" {
+		return LoadTimeZone(name)
+	}
+	if name := strings.TrimPrefix(os.Getenv("TZ"), ":"); name != "" {
+		return LoadTimeZone(name)
+	}
+	// the system time zone is named after its zoneinfo file
+	if target, err := os.Readlink("/etc/localtime"); err == nil {
+		if _, name, ok := strings.Cut(target, "zoneinfo/"); ok {
+			if tz, err := LoadTimeZone(name); err == nil {
+				return tz, nil
+			}
+		}
+	}
+	return TimeZone{Location: time.Local}, nil
+}
+
+// parseTime parses an RFC3339 time, a time without a UTC offset or a natural-language expression in the time zone
+func (tz TimeZone) parseTime(s string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	for _, layout := range localTimeLayouts {
+		if t, err := time.ParseInLocation(layout, s, tz.Location); err == nil {
+			return t, nil
+		}
+	}
+	t, err := parseNaturalTime(s, time.Now().In(tz.Location))
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid time '%s', expected RFC3339, YYYY-MM-DD HH:MM or an expression like 'tomorrow at 9': %v", s, err)
+	}
+	return t, nil
+}
+
+// dryRun prints the time a command would resolve to instead of sending it
+func (tz TimeZone) dryRun(cmdName string, at *time.Time, duration time.Duration) {
+	switch {
+	case at != nil:
+		fmt.Printf("%s would be due at %s\n", cmdName, at.In(tz.Location).Format(dryRunLayout))
+	case duration != 0:
+		fmt.Printf("%s would be due at %s\n", cmdName, time.Now().Add(duration).In(tz.Location).Format(dryRunLayout))
+	default:
+		fmt.Printf("%s would keep the due time\n", cmdName)
+	}
+}
+
+// render converts the time fields of a JSON document to the time zone, any other string is left untouched
+// and the document is returned as is when it cannot be decoded
+func (tz TimeZone) render(data []byte) []byte {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var (
+		buf    bytes.Buffer
+		scopes []jsonScope
+	)
+	for {
+		token, err := dec.Token()
+		if err == io.EOF && len(scopes) == 0 {
+			return buf.Bytes()
+		}
+		if err != nil {
+			return data
+		}
+		if delim, ok := token.(json.Delim); ok && (delim == '}' || delim == ']') {
+			buf.WriteByte(byte(delim))
+			scopes = scopes[:len(scopes)-1]
+			continue
+		}
+		var scope *jsonScope
+		isKey := false
+		if len(scopes) > 0 {
+			scope = &scopes[len(scopes)-1]
+			isKey = scope.object && scope.n%2 == 0
+			switch {
+			case scope.object && !isKey:
+				buf.WriteByte(':')
+			case scope.n > 0:
+				buf.WriteByte(',')
+			}
+			scope.n++
+		}
+		switch token := token.(type) {
+		case json.Delim:
+			buf.WriteByte(byte(token))
+			scopes = append(scopes, jsonScope{object: token == '{'})
+		case string:
+			if isKey {
+				scope.key = token
+			} else if scope != nil && scope.object && timeFields[scope.key] {
+				if t, err := time.Parse(time.RFC3339Nano, token); err == nil {
+					token = t.In(tz.Location).Format(time.RFC3339Nano)
+				}
+			}
+			encoded, _ := json.Marshal(token)
+			buf.Write(encoded)
+		default:
+			encoded, _ := json.Marshal(token)
+			buf.Write(encoded)
+		}
+	}
+}
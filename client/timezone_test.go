@@ -0,0 +1,48 @@
+package client
+
+import (
+	"testing"
+)
+
+func TestTimeZoneRender(t *testing.T) {
+	tokyo, err := LoadTimeZone("Asia/Tokyo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name string
+		data string
+		want string
+	}{
+		{
+			name: "time fields",
+			data: `{"id":1,"due_at":"2030-01-01T00:00:00Z","fired_at":null,"recurrence":{"rule":"FREQ=DAILY","current":"2030-01-01T00:00:00.5Z"}}`,
+			want: `{"id":1,"due_at":"2030-01-01T09:00:00+09:00","fired_at":null,"recurrence":{"rule":"FREQ=DAILY","current":"2030-01-01T09:00:00.5+09:00"}}`,
+		},
+		{
+			name: "lists of objects",
+			data: `[{"created_at":"2030-01-01T12:00:00+02:00","attempts":3},{"created_at":"2030-01-02T00:00:00Z","ok":true}]`,
+			want: `[{"created_at":"2030-01-01T19:00:00+09:00","attempts":3},{"created_at":"2030-01-02T09:00:00+09:00","ok":true}]`,
+		},
+		{
+			name: "other strings are left untouched",
+			data: `{"title":"2030-01-01T00:00:00Z","message":"{\"due_at\":\"2030-01-01T00:00:00Z\"}","tags":["2030-01-01T00:00:00Z"],"due_at":"soon"}`,
+			want: `{"title":"2030-01-01T00:00:00Z","message":"{\"due_at\":\"2030-01-01T00:00:00Z\"}","tags":["2030-01-01T00:00:00Z"],"due_at":"soon"}`,
+		},
+		{
+			name: "large numbers are kept",
+			data: `{"submitted":18446744073709551615,"ratio":0.25}`,
+			want: `{"submitted":18446744073709551615,"ratio":0.25}`,
+		},
+		{
+			name: "invalid documents are returned as is",
+			data: `{"due_at":"2030-01-01T00:00:00Z"`,
+			want: `{"due_at":"2030-01-01T00:00:00Z"`,
+		},
+	}
+	for _, tt := range tests {
+		if got := string(tokyo.render([]byte(tt.data))); got != tt.want {
+			t.Errorf("%s: got %s, want %s", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,226 @@
+package client
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// defaultHour is the time of day of the expressions naming only a day, e.g. "tomorrow"
+const defaultHour = 9
+
+// weekdayNames maps the full & short weekday names to weekdays
+var weekdayNames = map[string]time.Weekday{
+	"sunday": time.Sunday, "sun": time.Sunday,
+	"monday": time.Monday, "mon": time.Monday,
+	"tuesday": time.Tuesday, "tue": time.Tuesday,
+	"wednesday": time.Wednesday, "wed": time.Wednesday,
+	"thursday": time.Thursday, "thu": time.Thursday,
+	"friday": time.Friday, "fri": time.Friday,
+	"saturday": time.Saturday, "sat": time.Saturday,
+}
+
+var (
+	clockPattern  = regexp.MustCompile(`^(\d{1,2})(?::(\d{2}))?(am|pm)?$`)
+	amountPattern = regexp.MustCompile(`^(\d+)([a-z]*)$`)
+)
+
+// naturalTime represents the parts of a natural-language time expression
+type naturalTime struct {
+	date     time.Time
+	hasDate  bool
+	weekday  time.Weekday
+	next     bool
+	hasDay   bool
+	minutes  int
+	hasClock bool
+}
+
+// parseNaturalTime parses a time expression relative to now in the location of now:
+// "now", "in 2 weeks", "in 1 hour 30 minutes", "today", "tomorrow at 9", "friday 2pm", "next friday 14:00",
+// "2030-01-02" or "2030-01-02 at 9:30am". Days without a time of day are due at 9:00, a time of day alone
+// is due today or else tomorrow, a weekday is the upcoming one (today if the time is still ahead)
+// and "next" skips today. An explicit day never rolls over, so "today 8am" past 8:00 is rejected as being in the past
+func parseNaturalTime(expr string, now time.Time) (time.Time, error) {
+	tokens := strings.Fields(strings.ToLower(expr))
+	if len(tokens) == 0 {
+		return time.Time{}, fmt.Errorf("empty time expression")
+	}
+	if len(tokens) == 1 && tokens[0] == "now" {
+		return now, nil
+	}
+	if tokens[0] == "in" {
+		return parseRelativeTime(tokens[1:], now)
+	}
+
+	var nt naturalTime
+	for i := 0; i < len(tokens); i++ {
+		token := tokens[i]
+		switch {
+		case token == "at" || token == "on":
+		case token == "today" || token == "tomorrow":
+			if nt.hasDate || nt.hasDay {
+				return time.Time{}, fmt.Errorf("more than one day in '%s'", expr)
+			}
+			nt.date, nt.hasDate = now, true
+			if token == "tomorrow" {
+				nt.date = now.AddDate(0, 0, 1)
+			}
+		case token == "next" || token == "this":
+			if i+1 == len(tokens) {
+				return time.Time{}, fmt.Errorf("'%s' must be followed by a weekday", token)
+			}
+			if _, ok := weekdayNames[tokens[i+1]]; !ok {
+				return time.Time{}, fmt.Errorf("'%s' must be followed by a weekday", token)
+			}
+			nt.next = token == "next"
+		case token == "noon" || token == "midnight":
+			if nt.hasClock {
+				return time.Time{}, fmt.Errorf("more than one time of day in '%s'", expr)
+			}
+			nt.hasClock = true
+			if token == "noon" {
+				nt.minutes = 12 * 60
+			}
+		default:
+			if weekday, ok := weekdayNames[token]; ok {
+				if nt.hasDate || nt.hasDay {
+					return time.Time{}, fmt.Errorf("more than one day in '%s'", expr)
+				}
+				nt.weekday, nt.hasDay = weekday, true
+				continue
+			}
+			if date, err := time.ParseInLocation("2006-01-02", token, now.Location()); err == nil {
+				if nt.hasDate || nt.hasDay {
+					return time.Time{}, fmt.Errorf("more than one day in '%s'", expr)
+				}
+				nt.date, nt.hasDate = date, true
+				continue
+			}
+			// the meridiem may be a separate token, e.g. "9 pm"
+			if i+1 < len(tokens) && (tokens[i+1] == "am" || tokens[i+1] == "pm") {
+				token += tokens[i+1]
+				i++
+			}
+			minutes, ok := parseClockTime(token)
+			if !ok {
+				return time.Time{}, fmt.Errorf("unrecognized '%s' in '%s'", token, expr)
+			}
+			if nt.hasClock {
+				return time.Time{}, fmt.Errorf("more than one time of day in '%s'", expr)
+			}
+			nt.minutes, nt.hasClock = minutes, true
+		}
+	}
+	if !nt.hasDate && !nt.hasDay && !nt.hasClock {
+		return time.Time{}, fmt.Errorf("no day or time of day in '%s'", expr)
+	}
+	t := nt.resolve(now)
+	if t.Before(now) {
+		return time.Time{}, fmt.Errorf("'%s' is in the past (%s)", expr, t.Format(dryRunLayout))
+	}
+	return t, nil
+}
+
+// resolve retrieves the time the expression parts refer to
+func (nt naturalTime) resolve(now time.Time) time.Time {
+	minutes := defaultHour * 60
+	if nt.hasClock {
+		minutes = nt.minutes
+	}
+	at := func(day time.Time, offset int) time.Time {
+		y, m, d := day.Date()
+		return time.Date(y, m, d+offset, 0, minutes, 0, 0, now.Location())
+	}
+	switch {
+	case nt.hasDate:
+		return at(nt.date, 0)
+	case nt.hasDay:
+		days := (int(nt.weekday) - int(now.Weekday()) + 7) % 7
+		if days == 0 && (nt.next || !at(now, 0).After(now)) {
+			days = 7
+		}
+		return at(now, days)
+	}
+	if t := at(now, 0); t.After(now) {
+		return t
+	}
+	return at(now, 1)
+}
+
+// parseRelativeTime parses the amounts following "in", e.g. "2 weeks", "1h 30m" or "3 days and 2 hours"
+func parseRelativeTime(tokens []string, now time.Time) (time.Time, error) {
+	t := now
+	parsed := false
+	for i := 0; i < len(tokens); i++ {
+		if tokens[i] == "and" || tokens[i] == "," {
+			continue
+		}
+		match := amountPattern.FindStringSubmatch(strings.TrimSuffix(tokens[i], ","))
+		if match == nil {
+			return time.Time{}, fmt.Errorf("expected an amount, got '%s'", tokens[i])
+		}
+		amount, err := strconv.Atoi(match[1])
+		if err != nil {
+			return time.Time{}, fmt.Errorf("invalid amount '%s'", match[1])
+		}
+		unit := match[2]
+		if unit == "" {
+			if i+1 == len(tokens) {
+				return time.Time{}, fmt.Errorf("missing unit after '%s'", tokens[i])
+			}
+			i++
+			unit = strings.TrimSuffix(tokens[i], ",")
+		}
+		switch strings.TrimSuffix(unit, "s") {
+		case "m", "min", "minute":
+			t = t.Add(time.Duration(amount) * time.Minute)
+		case "h", "hr", "hour":
+			t = t.Add(time.Duration(amount) * time.Hour)
+		case "d", "day":
+			t = t.AddDate(0, 0, amount)
+		case "w", "wk", "week":
+			t = t.AddDate(0, 0, 7*amount)
+		case "mo", "month":
+			t = t.AddDate(0, amount, 0)
+		case "y", "yr", "year":
+			t = t.AddDate(amount, 0, 0)
+		default:
+			return time.Time{}, fmt.Errorf("unknown unit '%s'", unit)
+		}
+		parsed = true
+	}
+	if !parsed {
+		return time.Time{}, fmt.Errorf("'in' must be followed by an amount of time, e.g. 'in 2 weeks'")
+	}
+	return t, nil
+}
+
+// parseClockTime parses a time of day like 9, 9am, 9:30pm or 14:00 to minutes since midnight
+func parseClockTime(s string) (int, bool) {
+	match := clockPattern.FindStringSubmatch(s)
+	if match == nil {
+		return 0, false
+	}
+	h, _ := strconv.Atoi(match[1])
+	m := 0
+	if match[2] != "" {
+		m, _ = strconv.Atoi(match[2])
+	}
+	switch match[3] {
+	case "am", "pm":
+		if h < 1 || h > 12 {
+			return 0, false
+		}
+		h %= 12
+		if match[3] == "pm" {
+			h += 12
+		}
+	}
+	if h > 23 || m > 59 {
+		return 0, false
+	}
+	return h*60 + m, true
+}
@@ -0,0 +1,94 @@
+package client
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseNaturalTime(t *testing.T) {
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 2030-01-01 is a tuesday
+	now := time.Date(2030, 1, 1, 10, 30, 0, 0, time.UTC)
+	at := func(d, h, m int) time.Time {
+		return time.Date(2030, 1, d, h, m, 0, 0, time.UTC)
+	}
+	// the clocks of New York move forward on 2030-03-10
+	beforeDST := time.Date(2030, 3, 9, 12, 0, 0, 0, newYork)
+	tests := []struct {
+		expr string
+		now  time.Time
+		want time.Time
+		err  bool
+	}{
+		{expr: "now", want: now},
+		{expr: "in 2 weeks", want: at(15, 10, 30)},
+		{expr: "in 1 hour 30 minutes", want: at(1, 12, 0)},
+		{expr: "in 1h 30m", want: at(1, 12, 0)},
+		{expr: "in 3 days and 2 hours", want: at(4, 12, 30)},
+		{expr: "in 1 mo", want: time.Date(2030, 2, 1, 10, 30, 0, 0, time.UTC)},
+		{expr: "in 1 day", now: beforeDST, want: time.Date(2030, 3, 10, 12, 0, 0, 0, newYork)},
+		{expr: "in 24 hours", now: beforeDST, want: time.Date(2030, 3, 10, 13, 0, 0, 0, newYork)},
+		{expr: "tomorrow 9am", now: beforeDST, want: time.Date(2030, 3, 10, 9, 0, 0, 0, newYork)},
+		{expr: "today 11am", want: at(1, 11, 0)},
+		{expr: "tomorrow", want: at(2, 9, 0)},
+		{expr: "tomorrow at 9:30pm", want: at(2, 21, 30)},
+		{expr: "tomorrow 9 pm", want: at(2, 21, 0)},
+		{expr: "Tomorrow Noon", want: at(2, 12, 0)},
+		{expr: "14:00", want: at(1, 14, 0)},
+		{expr: "9", want: at(2, 9, 0)},
+		{expr: "midnight", want: at(2, 0, 0)},
+		{expr: "tue 2pm", want: at(1, 14, 0)},
+		{expr: "tuesday", want: at(8, 9, 0)},
+		{expr: "next tuesday 2pm", want: at(8, 14, 0)},
+		{expr: "friday", want: at(4, 9, 0)},
+		{expr: "this friday 5pm", want: at(4, 17, 0)},
+		{expr: "on friday at 12am", want: at(4, 0, 0)},
+		{expr: "2030-01-02", want: at(2, 9, 0)},
+		{expr: "2030-01-02 at 9:30am", want: at(2, 9, 30)},
+		// explicit days in the past are rejected instead of rolling over
+		{expr: "today", err: true},
+		{expr: "today 8am", err: true},
+		{expr: "today midnight", err: true},
+		{expr: "2029-12-31", err: true},
+		{expr: "", err: true},
+		{expr: "in", err: true},
+		{expr: "in 2", err: true},
+		{expr: "in two weeks", err: true},
+		{expr: "in 2 fortnights", err: true},
+		{expr: "next", err: true},
+		{expr: "next week", err: true},
+		{expr: "today tomorrow", err: true},
+		{expr: "friday monday", err: true},
+		{expr: "friday 2030-01-02", err: true},
+		{expr: "9am 10am", err: true},
+		{expr: "noon 1pm", err: true},
+		{expr: "13pm", err: true},
+		{expr: "0am", err: true},
+		{expr: "25:00", err: true},
+		{expr: "9:60", err: true},
+		{expr: "someday", err: true},
+	}
+	for _, tt := range tests {
+		from := now
+		if !tt.now.IsZero() {
+			from = tt.now
+		}
+		got, err := parseNaturalTime(tt.expr, from)
+		if tt.err {
+			if err == nil {
+				t.Errorf("%q: got %v, want an error", tt.expr, got)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%q: %v", tt.expr, err)
+			continue
+		}
+		if !got.Equal(tt.want) {
+			t.Errorf("%q: got %v, want %v", tt.expr, got, tt.want)
+		}
+	}
+}
@@ -12,14 +12,20 @@ func main() {
 	helpFlag := flag.Bool("help", false, "Display a helpful message")
 	flag.Parse()
 
-	s := client.NewSwitch(*backendURLFlag)
+	timeZone, err := client.DefaultTimeZone()
+	if err != nil {
+		fmt.Println("time zone error:", err)
+		os.Exit(2)
+	}
+
+	s := client.NewSwitch(*backendURLFlag, timeZone)
 
 	if *helpFlag || len(os.Args) == 1 {
 		s.Help()
 		return
 	}
 
-	err := s.Switch()
+	err = s.Switch()
 	if err != nil {
 		fmt.Println("cmd switch error:", err)
 		os.Exit(2)
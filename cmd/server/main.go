@@ -7,24 +7,214 @@ import (
 	"github.com/muhtutorials/reminders_cli/server/services"
 	"log"
 	"os"
+	"path/filepath"
+	"strings"
 	"syscall"
+	"time"
 )
 
+// listFlag represents a flag that may be repeated, values are also split by sep unless it is empty
+type listFlag struct {
+	sep    string
+	values []string
+	set    bool
+}
+
+func (f *listFlag) String() string {
+	return strings.Join(f.values, ",")
+}
+
+func (f *listFlag) Set(value string) error {
+	f.set = true
+	values := []string{value}
+	if f.sep != "" {
+		values = strings.Split(value, f.sep)
+	}
+	for _, v := range values {
+		if v = strings.TrimSpace(v); v != "" {
+			f.values = append(f.values, v)
+		}
+	}
+	return nil
+}
+
 func main() {
+	notifierFlag, routeFlag, quietFlag, priorityFlag := listFlag{sep: ","}, listFlag{}, listFlag{}, listFlag{sep: ","}
+	flag.Var(&notifierFlag, "notifier", "Notifier API URL formatted as [name[@priority]=]url, repeat or separate by commas for several targets (default http://localhost:5000)")
+	flag.Var(&quietFlag, "quiet_hours", "Quiet hours formatted as \"[days] HH:MM-HH:MM [time zone]\" (e.g. \"mon-fri 22:00-07:00 Europe/Berlin\"), may be repeated")
+	flag.Var(&routeFlag, "notifier_route", "Notifier routing rule formatted as field=value:targets, field~value:targets or *:targets, may be repeated")
+	flag.Var(&priorityFlag, "priority_channel", "Channel of the reminders with a priority and no channel of their own formatted as priority=channel (e.g. urgent=command), may be repeated")
 	var (
 		dbFlag          = flag.String("db", "db.json", "Path to db.json file")
 		dbCfgFlag       = flag.String("db_cfg", ".db.config.json", "Path to .db.config.json file")
 		addrFlag        = flag.String("addr", ":8000", "HTTP server address")
-		notifierURLFlag = flag.String("notifier", "http://localhost:5000", "Notifier API URL")
+		callbackURLFlag = flag.String("callback", "", "Backend API URL the notifier calls back (default http://localhost<addr>)")
+		deliveryTTLFlag = flag.Duration("delivery_timeout", 2*time.Minute, "Time to wait for the notifier to call back before retrying")
+		channelFlag     = flag.String("channel", services.ChannelNotifier, "Default notification channel: notifier, webhook, command, sink or email")
+		webhookFlag     = flag.String("webhook", "", "URL the webhook channel posts reminders to")
+		commandFlag     = flag.String("command", "", "Command the command channel executes for every reminder")
+		commandTTLFlag  = flag.Duration("command_timeout", time.Minute, "Time the notification command is allowed to run")
+		sinkFlag        = flag.String("sink", "", "File the sink channel appends reminders to, - for stdout")
+		smtpFlag        = flag.String("smtp", "", "SMTP server address (host:port) the email channel sends reminders through")
+		smtpUserFlag    = flag.String("smtp_user", "", "SMTP username, enables PLAIN auth")
+		smtpPassFlag    = flag.String("smtp_password", os.Getenv("SMTP_PASSWORD"), "SMTP password (default $SMTP_PASSWORD)")
+		smtpFromFlag    = flag.String("smtp_from", "", "Sender address of the reminder emails")
+		smtpToFlag      = flag.String("smtp_to", "", "Comma separated recipient addresses of the reminder emails")
+		smtpTLSFlag     = flag.Bool("smtp_starttls", true, "Require STARTTLS before authenticating and sending emails")
+		outboxDBFlag    = flag.String("outbox_db", "", "Path to outbox.json file (default next to the db file)")
+		deliveryTryFlag = flag.Int("delivery_attempts", 10, "Number of attempts to send a notification before it is dead")
+		deliveryBOFlag  = flag.Duration("delivery_backoff", time.Second, "Delay before the first notification retry, doubled on every retry")
+		deliveryMaxFlag = flag.Duration("delivery_backoff_max", 5*time.Minute, "Maximum delay between notification retries")
+		thresholdFlag   = flag.Int("breaker_threshold", 5, "Consecutive notifier failures opening its circuit breaker")
+		probeFlag       = flag.Duration("breaker_probe", 10*time.Second, "Interval of the notifier health probes while its circuit breaker is open")
+		workersFlag     = flag.Int("notifier_workers", 4, "Number of notifications sent concurrently")
+		queueFlag       = flag.Int("notifier_queue", 64, "Number of notifications waiting for a worker before new ones are postponed")
+		webhooksDBFlag  = flag.String("webhooks_db", "", "Path to webhooks.json file (default next to the db file)")
+		attemptsFlag    = flag.Int("webhook_attempts", 8, "Number of attempts to deliver a webhook event")
+		backoffFlag     = flag.Duration("webhook_backoff", time.Second, "Delay before the first webhook retry, doubled on every retry")
+		catchUpFlag     = flag.String("catch_up", string(services.CatchUpLatest), "Policy for the reminders missed while the server was down: fire, latest, mark or coalesce")
+		dropLowFlag     = flag.Bool("quiet_drop_low", false, "Drop the low priority reminders due during quiet hours or do-not-disturb instead of holding them")
 	)
 	flag.Parse()
 
+	catchUpPolicy, err := services.ParseCatchUpPolicy(*catchUpFlag)
+	if err != nil {
+		log.Fatalf("invalid --catch_up: %v", err)
+	}
+
+	clock := services.NewRealClock()
 	db := repositories.NewDB(*dbFlag, *dbCfgFlag)
 	repo := repositories.NewReminders(db)
-	service := services.NewReminders(repo)
-	backend := server.NewBackend(*addrFlag, service)
-	saver := services.NewSaver(service)
-	notifier := services.NewNotifier(*notifierURLFlag, service)
+	callbackURL := *callbackURLFlag
+	if callbackURL == "" && strings.HasPrefix(*addrFlag, ":") {
+		callbackURL = "http://localhost" + *addrFlag
+	} else if callbackURL == "" {
+		callbackURL = "http://" + *addrFlag
+	}
+	channels := services.NewChannels(*channelFlag)
+	if !notifierFlag.set {
+		notifierFlag.values = []string{"http://localhost:5000"}
+	}
+	var rules []services.RoutingRule
+	for _, value := range routeFlag.values {
+		rule, err := services.ParseRoutingRule(value)
+		if err != nil {
+			log.Fatalf("invalid notifier route: %v", err)
+		}
+		rules = append(rules, rule)
+	}
+	router := services.NewNotifierRouter(rules)
+	for _, value := range notifierFlag.values {
+		target, err := services.ParseNotifierTarget(value)
+		if err != nil {
+			log.Fatalf("invalid notifier: %v", err)
+		}
+		httpClient := services.NewHTTPClient(target.URL, callbackURL)
+		name := services.ChannelNotifier + "/" + target.Name
+		router.Add(target, services.NewCircuitBreaker(name, httpClient, httpClient.Health, *thresholdFlag, *probeFlag, clock))
+	}
+	if err := router.Validate(); err != nil {
+		log.Fatalf("invalid notifier targets: %v", err)
+	}
+	if router.Len() > 0 {
+		channels.Register(services.ChannelNotifier, router)
+	}
+	if *webhookFlag != "" {
+		channels.Register(services.ChannelWebhook, services.NewWebhookChannel(*webhookFlag, callbackURL))
+	}
+	if *commandFlag != "" {
+		channels.Register(services.ChannelCommand, services.NewCommandChannel(strings.Fields(*commandFlag), *commandTTLFlag))
+	}
+	if *sinkFlag != "" {
+		channels.Register(services.ChannelSink, services.NewSinkChannel(*sinkFlag))
+	}
+	if *smtpFlag != "" {
+		if *smtpFromFlag == "" || *smtpToFlag == "" {
+			log.Fatalf("the email channel requires both --smtp_from and --smtp_to")
+		}
+		channels.Register(services.ChannelEmail, services.NewEmailChannel(services.SMTPConfig{
+			Addr:     *smtpFlag,
+			Username: *smtpUserFlag,
+			Password: *smtpPassFlag,
+			From:     *smtpFromFlag,
+			To:       strings.Split(*smtpToFlag, ","),
+			StartTLS: *smtpTLSFlag,
+			Timeout:  30 * time.Second,
+		}, clock))
+	}
+	for _, value := range priorityFlag.values {
+		name, channel, ok := strings.Cut(value, "=")
+		if !ok {
+			log.Fatalf("invalid --priority_channel '%s', expected priority=channel", value)
+		}
+		priority, err := services.ParsePriority(name)
+		if err != nil {
+			log.Fatalf("invalid --priority_channel: %v", err)
+		}
+		channels.Route(priority, channel)
+	}
+	if err := channels.Validate(); err != nil {
+		log.Fatalf("invalid notification channels: %v", err)
+	}
+
+	webhooksDB := *webhooksDBFlag
+	if webhooksDB == "" {
+		webhooksDB = filepath.Join(filepath.Dir(*dbFlag), "webhooks.json")
+	}
+	if *attemptsFlag < 1 {
+		log.Fatalf("invalid --webhook_attempts: %d, expected at least 1", *attemptsFlag)
+	}
+	if *backoffFlag <= 0 {
+		log.Fatalf("invalid --webhook_backoff: %v, expected a positive duration", *backoffFlag)
+	}
+	webhooks := services.NewWebhooks(repositories.NewWebhooks(webhooksDB), *attemptsFlag, *backoffFlag, clock)
+
+	var windows []services.QuietWindow
+	for _, value := range quietFlag.values {
+		window, err := services.ParseQuietWindow(value)
+		if err != nil {
+			log.Fatalf("invalid --quiet_hours: %v", err)
+		}
+		windows = append(windows, window)
+	}
+	quiet := services.NewQuietHours(windows, *dropLowFlag, clock)
+	scheduler := services.NewScheduler()
+	service := services.NewReminders(repo, scheduler, channels, webhooks, catchUpPolicy, clock)
+	outboxDB := *outboxDBFlag
+	if outboxDB == "" {
+		outboxDB = filepath.Join(filepath.Dir(*dbFlag), "outbox.json")
+	}
+	if *deliveryTryFlag < 1 {
+		log.Fatalf("invalid --delivery_attempts: %d, expected at least 1", *deliveryTryFlag)
+	}
+	if *deliveryBOFlag <= 0 {
+		log.Fatalf("invalid --delivery_backoff: %v, expected a positive duration", *deliveryBOFlag)
+	}
+	if *deliveryMaxFlag < *deliveryBOFlag {
+		log.Fatalf("invalid --delivery_backoff_max: %v, expected at least --delivery_backoff (%v)", *deliveryMaxFlag, *deliveryBOFlag)
+	}
+	backoff := services.Backoff{
+		Attempts: *deliveryTryFlag,
+		Base:     *deliveryBOFlag,
+		Max:      *deliveryMaxFlag,
+	}
+	deliveries := services.NewDeliveries(repositories.NewOutbox(outboxDB), service, *deliveryTTLFlag, backoff, clock)
+	if *workersFlag < 1 {
+		log.Fatalf("invalid --notifier_workers: %d, expected at least 1", *workersFlag)
+	}
+	if *queueFlag < 0 {
+		log.Fatalf("invalid --notifier_queue: %d, expected 0 or more", *queueFlag)
+	}
+	if *thresholdFlag < 1 {
+		log.Fatalf("invalid --breaker_threshold: %d, expected at least 1", *thresholdFlag)
+	}
+	if *probeFlag <= 0 {
+		log.Fatalf("invalid --breaker_probe: %v, expected a positive duration", *probeFlag)
+	}
+	pool := services.NewWorkerPool(*workersFlag, *queueFlag, clock)
+	backend := server.NewBackend(*addrFlag, service, deliveries, webhooks, quiet, pool, channels)
+	saver := services.NewSaver(service, clock)
+	notifier := services.NewNotifier(channels, service, scheduler, deliveries, pool, quiet, clock)
 
 	if err := db.Start(); err != nil {
 		log.Fatalf("could not start file database service: %v", err)
@@ -39,5 +229,5 @@ func main() {
 	go notifier.Start()
 
 	signals := []os.Signal{syscall.SIGINT, syscall.SIGTERM}
-	server.ListenForSignals(signals, db, backend, saver, notifier)
+	server.ListenForSignals(signals, db, backend, saver, notifier, webhooks)
 }
@@ -13,27 +13,69 @@ app.get("/health", (req, res) => {
     res.status(200).send();
 });
 app.post("/notify", (req, res) => {
-    notify(req.body, reply => res.send(reply));
+    const { callback_url } = req.body;
+    if (!callback_url) {
+        // legacy servers wait for the user action on the same request
+        notify(req.body, reply => res.send(reply));
+        return;
+    }
+    // the delivery is acknowledged right away and the user action is called back later
+    res.status(202).send();
+    notify(req.body, reply => callback(callback_url, reply));
 });
 
-const notify = ({ title, message }, callback) => {
+// urgency & sound are hints derived from the reminder priority, older servers send neither
+const notify = ({ title, message, actions, urgency, sound }, callback) => {
+    const labels = (actions || []).map(action => action.label);
     notifier.notify(
         {
             title: title || "Unknown title",
             message: message || "Unknown message",
             icon: path.join(__dirname, "scorpion.jpg"),
-            sound: true,
+            sound: sound !== false,
+            urgency: urgency || "normal",
             wait: true,
-            reply: true,
+            reply: labels.length === 0,
+            actions: labels.length > 0 ? labels : undefined,
             closeLabel: "Completed?",
             timeout: 15
         },
-        (err, response, reply) => {
-            callback(reply)
+        (err, response, metadata) => {
+            callback(result(response, metadata || {}))
         }
     );
 }
 
+// result maps the notification outcome to the user action reported to the backend:
+// dismissed, clicked, snoozed (replying with a duration like "10m"), timeout or a custom button
+const result = (response, { activationType, activationValue }) => {
+    switch (activationType || response) {
+        case "actionClicked":
+            return { action: "button", button: activationValue };
+        case "replied":
+            return { action: "snoozed", snooze: (activationValue || "").trim() || undefined };
+        case "contentsClicked":
+        case "activate":
+        case "clicked":
+            return { action: "clicked" };
+        case "timeout":
+            return { action: "timeout" };
+        case "closed":
+        case "dismissed":
+            return { action: "dismissed" };
+        default:
+            return { action: activationType || response };
+    }
+}
+
+const callback = (url, reply) => {
+    fetch(url, {
+        method: "POST",
+        headers: { "Content-Type": "application/json" },
+        body: JSON.stringify(reply),
+    }).catch(err => console.error(`could not call back ${url}: ${err.message}`));
+}
+
 app.listen(port, () => {
     console.log(`Server is up and running on port: ${port}...`);
-});
\ No newline at end of file
+});
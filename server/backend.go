@@ -12,29 +12,57 @@ import (
 )
 
 type Backend struct {
-	server  *http.Server
-	service *services.Reminders
+	server     *http.Server
+	service    *services.Reminders
+	deliveries *services.Deliveries
+	webhooks   *services.Webhooks
 }
 
-func NewBackend(addr string, service *services.Reminders) *Backend {
-	cfg := controllers.RouterConfig{Service: service}
+func NewBackend(
+	addr string,
+	service *services.Reminders,
+	deliveries *services.Deliveries,
+	webhooks *services.Webhooks,
+	quiet *services.QuietHours,
+	pool *services.WorkerPool,
+	channels *services.Channels,
+) *Backend {
+	cfg := controllers.RouterConfig{
+		Service:    service,
+		Deliveries: deliveries,
+		Webhooks:   webhooks,
+		DND:        quiet,
+		Metrics:    pool,
+		Health:     channels,
+	}
 	router := controllers.NewRouter(cfg)
 	return &Backend{
 		server: &http.Server{
 			Addr:    addr,
 			Handler: router,
 		},
-		service: service,
+		service:    service,
+		deliveries: deliveries,
+		webhooks:   webhooks,
 	}
 }
 
 // Start starts the initialized server (backend) application
 func (b *Backend) Start() error {
 	log.Println("application started on address", b.server.Addr)
-	err := b.service.Populate()
+	err := b.webhooks.Populate()
+	if err != nil {
+		return models.WrapError("could not initialize webhooks service", err)
+	}
+	pending, err := b.deliveries.Load()
+	if err != nil {
+		return models.WrapError("could not initialize notification outbox", err)
+	}
+	err = b.service.Populate(pending)
 	if err != nil {
 		return models.WrapError("could not initialize reminders service", err)
 	}
+	b.deliveries.Populate()
 
 	err = b.server.ListenAndServe()
 	if err == http.ErrServerClosed {
@@ -0,0 +1,27 @@
+package controllers
+
+import (
+	"github.com/muhtutorials/reminders_cli/server/models"
+	"github.com/muhtutorials/reminders_cli/server/transport"
+	"net/http"
+)
+
+type canceller interface {
+	Cancel(id int) (models.Reminder, error)
+}
+
+func cancelReminder(service canceller) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id, err := parseIDParam(r.Context())
+		if err != nil {
+			transport.SendError(w, err)
+			return
+		}
+		reminder, err := service.Cancel(id)
+		if err != nil {
+			transport.SendError(w, err)
+			return
+		}
+		transport.SendJSON(w, reminder, http.StatusOK)
+	})
+}
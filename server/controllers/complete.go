@@ -0,0 +1,27 @@
+package controllers
+
+import (
+	"github.com/muhtutorials/reminders_cli/server/models"
+	"github.com/muhtutorials/reminders_cli/server/transport"
+	"net/http"
+)
+
+type completer interface {
+	Complete(id int) (models.Reminder, error)
+}
+
+func completeReminder(service completer) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id, err := parseIDParam(r.Context())
+		if err != nil {
+			transport.SendError(w, err)
+			return
+		}
+		reminder, err := service.Complete(id)
+		if err != nil {
+			transport.SendError(w, err)
+			return
+		}
+		transport.SendJSON(w, reminder, http.StatusOK)
+	})
+}
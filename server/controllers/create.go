@@ -16,10 +16,18 @@ type creator interface {
 func createReminder(service creator) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		var body struct {
-			Title       string        `json:"title"`
-			Message     string        `json:"message"`
-			Duration    time.Duration `json:"duration"`
-			RetryPeriod time.Duration `json:"retry_period"`
+			Title       string          `json:"title"`
+			Message     string          `json:"message"`
+			Duration    time.Duration   `json:"duration"`
+			DueAt       time.Time       `json:"due_at"`
+			RetryPeriod time.Duration   `json:"retry_period"`
+			Repeat      string          `json:"repeat"`
+			Actions     []models.Action `json:"actions"`
+			Channel     string          `json:"channel"`
+			QuietHours  []string        `json:"quiet_hours"`
+			TimeZone    string          `json:"time_zone"`
+			Tags        []string        `json:"tags"`
+			Priority    string          `json:"priority"`
 		}
 
 		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
@@ -30,7 +38,15 @@ func createReminder(service creator) http.Handler {
 			Title:       body.Title,
 			Message:     body.Message,
 			Duration:    body.Duration,
+			DueAt:       body.DueAt,
 			RetryPeriod: body.RetryPeriod,
+			Repeat:      body.Repeat,
+			Actions:     body.Actions,
+			Channel:     body.Channel,
+			QuietHours:  body.QuietHours,
+			TimeZone:    body.TimeZone,
+			Tags:        body.Tags,
+			Priority:    body.Priority,
 		})
 		if err != nil {
 			transport.SendError(w, err)
@@ -0,0 +1,27 @@
+package controllers
+
+import (
+	"github.com/muhtutorials/reminders_cli/server/models"
+	"github.com/muhtutorials/reminders_cli/server/transport"
+	"net/http"
+)
+
+type DeliveriesService interface {
+	deliveriesLister
+	notificationResolver
+}
+
+type deliveriesLister interface {
+	List(status string) ([]models.Delivery, error)
+}
+
+func listDeliveries(service deliveriesLister) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		deliveries, err := service.List(r.URL.Query().Get("status"))
+		if err != nil {
+			transport.SendError(w, err)
+			return
+		}
+		transport.SendJSON(w, deliveries, http.StatusOK)
+	})
+}
@@ -0,0 +1,57 @@
+package controllers
+
+import (
+	"encoding/json"
+	"github.com/muhtutorials/reminders_cli/server/models"
+	"github.com/muhtutorials/reminders_cli/server/services"
+	"github.com/muhtutorials/reminders_cli/server/transport"
+	"net/http"
+	"time"
+)
+
+type dndSetter interface {
+	SetDND(body services.DNDBody) (services.DNDState, error)
+}
+
+type dndReporter interface {
+	DND() services.DNDState
+}
+
+type DNDService interface {
+	dndSetter
+	dndReporter
+}
+
+func setDND(service dndSetter) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Enabled  *bool         `json:"enabled"`
+			Duration time.Duration `json:"duration"`
+			Until    time.Time     `json:"until"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			transport.SendError(w, models.InvalidJSONError{Message: err.Error()})
+			return
+		}
+		if body.Enabled == nil {
+			transport.SendError(w, models.FormatValidationError{Message: "body must contain 'enabled'"})
+			return
+		}
+		state, err := service.SetDND(services.DNDBody{
+			Enabled:  *body.Enabled,
+			Duration: body.Duration,
+			Until:    body.Until,
+		})
+		if err != nil {
+			transport.SendError(w, err)
+			return
+		}
+		transport.SendJSON(w, state, http.StatusOK)
+	})
+}
+
+func getDND(service dndReporter) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		transport.SendJSON(w, service.DND(), http.StatusOK)
+	})
+}
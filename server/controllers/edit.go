@@ -18,22 +18,40 @@ func editReminder(service editor) http.Handler {
 		id, err := parseIDParam(r.Context())
 		if err != nil {
 			transport.SendError(w, err)
+			return
 		}
 		var body struct {
-			Title       string        `json:"title"`
-			Message     string        `json:"message"`
-			Duration    time.Duration `json:"duration"`
-			RetryPeriod time.Duration `json:"retry_period"`
+			Title       string          `json:"title"`
+			Message     string          `json:"message"`
+			Duration    time.Duration   `json:"duration"`
+			DueAt       time.Time       `json:"due_at"`
+			RetryPeriod time.Duration   `json:"retry_period"`
+			Repeat      string          `json:"repeat"`
+			Actions     []models.Action `json:"actions"`
+			Channel     string          `json:"channel"`
+			QuietHours  []string        `json:"quiet_hours"`
+			TimeZone    string          `json:"time_zone"`
+			Tags        []string        `json:"tags"`
+			Priority    string          `json:"priority"`
 		}
 		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
 			transport.SendError(w, models.InvalidJSONError{Message: err.Error()})
+			return
 		}
 		reminder, err := service.Edit(services.ReminderEditBody{
 			ID:          id,
 			Title:       body.Title,
 			Message:     body.Message,
 			Duration:    body.Duration,
+			DueAt:       body.DueAt,
 			RetryPeriod: body.RetryPeriod,
+			Repeat:      body.Repeat,
+			Actions:     body.Actions,
+			Channel:     body.Channel,
+			QuietHours:  body.QuietHours,
+			TimeZone:    body.TimeZone,
+			Tags:        body.Tags,
+			Priority:    body.Priority,
 		})
 		if err != nil {
 			transport.SendError(w, err)
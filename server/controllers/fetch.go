@@ -2,12 +2,13 @@ package controllers
 
 import (
 	"github.com/muhtutorials/reminders_cli/server/models"
+	"github.com/muhtutorials/reminders_cli/server/services"
 	"github.com/muhtutorials/reminders_cli/server/transport"
 	"net/http"
 )
 
 type fetcher interface {
-	Fetch(ids []int) ([]models.Reminder, error)
+	Fetch(ids []int, tags services.TagFilter) ([]models.Reminder, error)
 }
 
 func fetchReminders(service fetcher) http.Handler {
@@ -17,7 +18,7 @@ func fetchReminders(service fetcher) http.Handler {
 			transport.SendError(w, err)
 			return
 		}
-		reminders, err := service.Fetch(ids)
+		reminders, err := service.Fetch(ids, parseTagFilter(r.URL.Query()))
 		if err != nil {
 			transport.SendError(w, err)
 			return
@@ -1,9 +1,25 @@
 package controllers
 
-import "net/http"
+import (
+	"github.com/muhtutorials/reminders_cli/server/services"
+	"github.com/muhtutorials/reminders_cli/server/transport"
+	"net/http"
+)
 
-func health() http.Handler {
+type healthReporter interface {
+	Breakers() map[string]services.BreakerState
+}
+
+// health responds with 200 while the server is up, along with the circuit breakers of the notification channels
+func health(channels healthReporter) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		w.WriteHeader(http.StatusOK)
+		body := struct {
+			Status   string                           `json:"status"`
+			Breakers map[string]services.BreakerState `json:"breakers"`
+		}{
+			Status:   "ok",
+			Breakers: channels.Breakers(),
+		}
+		transport.SendJSON(w, body, http.StatusOK)
 	})
 }
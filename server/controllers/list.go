@@ -0,0 +1,95 @@
+package controllers
+
+import (
+	"fmt"
+	"github.com/muhtutorials/reminders_cli/server/models"
+	"github.com/muhtutorials/reminders_cli/server/services"
+	"github.com/muhtutorials/reminders_cli/server/transport"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type lister interface {
+	List(query services.ReminderQuery) (services.ReminderPage, error)
+}
+
+func listReminders(service lister) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query, err := parseListQuery(r.URL.Query())
+		if err != nil {
+			transport.SendError(w, err)
+			return
+		}
+		page, err := service.List(query)
+		if err != nil {
+			transport.SendError(w, err)
+			return
+		}
+		transport.SendJSON(w, page, http.StatusOK)
+	})
+}
+
+// parseListQuery parses the reminders listing query string
+func parseListQuery(values url.Values) (services.ReminderQuery, error) {
+	query := services.ReminderQuery{
+		Status: values.Get("status"),
+		Title:  values.Get("title"),
+		Sort:   values.Get("sort"),
+		Order:  values.Get("order"),
+		Next:   values.Get("next"),
+		Tags:   parseTagFilter(values),
+	}
+	if priority := values.Get("priority"); priority != "" {
+		query.Priorities = strings.Split(priority, ",")
+	}
+	if limit := values.Get("limit"); limit != "" {
+		n, err := strconv.Atoi(limit)
+		if err != nil {
+			return query, models.DataValidationError{Message: "invalid limit provided"}
+		}
+		query.Limit = n
+	}
+	if missed := values.Get("missed"); missed != "" {
+		b, err := strconv.ParseBool(missed)
+		if err != nil {
+			return query, models.DataValidationError{Message: "invalid missed provided, expected true or false"}
+		}
+		query.Missed = &b
+	}
+	ranges := []struct {
+		name string
+		tr   *services.TimeRange
+	}{
+		{"created", &query.Created},
+		{"modified", &query.Modified},
+		{"due", &query.Due},
+	}
+	for _, rng := range ranges {
+		var err error
+		if rng.tr.After, err = parseTimeParam(values, rng.name+"_after"); err != nil {
+			return query, err
+		}
+		if rng.tr.Before, err = parseTimeParam(values, rng.name+"_before"); err != nil {
+			return query, err
+		}
+	}
+	return query, nil
+}
+
+// parseTimeParam parses an optional RFC3339 query param
+func parseTimeParam(values url.Values, name string) (time.Time, error) {
+	v := values.Get(name)
+	if v == "" {
+		return time.Time{}, nil
+	}
+	t, err := time.Parse(time.RFC3339, v)
+	if err != nil {
+		return time.Time{}, models.DataValidationError{
+			Message: fmt.Sprintf("invalid %s provided, expected RFC3339 time", name),
+		}
+	}
+	return t, nil
+}
@@ -0,0 +1,22 @@
+package controllers
+
+import (
+	"github.com/muhtutorials/reminders_cli/server/services"
+	"github.com/muhtutorials/reminders_cli/server/transport"
+	"net/http"
+)
+
+type metricsReporter interface {
+	Stats() services.PoolStats
+}
+
+func metrics(pool metricsReporter) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body := struct {
+			NotifierPool services.PoolStats `json:"notifier_pool"`
+		}{
+			NotifierPool: pool.Stats(),
+		}
+		transport.SendJSON(w, body, http.StatusOK)
+	})
+}
@@ -27,32 +27,38 @@ type route struct {
 	handler http.Handler
 }
 
-// populate populates a route based on the actual serving request
-func (r *route) populate(req *http.Request) string {
+// match matches the route against the path of the serving request (query string is ignored)
+// and returns the url params populated with the actual request values
+func (r *route) match(req *http.Request) (map[string]urlParam, bool) {
+	if r.method != req.Method {
+		return nil, false
+	}
 	urlSlice := splitUrl(req.URL.Path)
 	pathSlice := splitUrl(r.path)
 	if len(pathSlice) != len(urlSlice) {
-		return ""
+		return nil, false
 	}
+	params := make(map[string]urlParam, len(r.params))
 	for name, param := range r.params {
 		regexParamValue := urlSlice[param.position]
 		regex := regexp.MustCompile(param.regex)
-		if regex.MatchString(regexParamValue) {
-			param.value = regexParamValue
-			r.params[name] = param
-			pathSlice[param.position] = regexParamValue
+		if !regex.MatchString(regexParamValue) {
+			return nil, false
 		}
+		param.value = regexParamValue
+		params[name] = param
+		pathSlice[param.position] = regexParamValue
 	}
-	pathStr := "/" + strings.Join(pathSlice, "/")
-	if req.URL.Path == pathStr {
-		return r.method + pathStr
+	for i := range pathSlice {
+		if pathSlice[i] != urlSlice[i] {
+			return nil, false
+		}
 	}
-	return ""
+	return params, true
 }
 
 type RegexMux struct {
-	routes    []*route
-	routesMap map[string]*route
+	routes []*route
 }
 
 // Get registers an HTTP handler with GET method
@@ -92,21 +98,19 @@ func (m *RegexMux) Handle(method, pattern string, handler http.Handler) {
 }
 
 func (m RegexMux) ServeHTTP(w http.ResponseWriter, r *http.Request) {
-	m.routesMap = map[string]*route{}
 	for _, rt := range m.routes {
-		key := rt.populate(r)
-		m.routesMap[key] = rt
-	}
-	key := r.Method + r.URL.Path
-	rt, ok := m.routesMap[key]
-	if !ok {
-		transport.SendError(w, models.NotFoundError{})
-	}
-	ctx := r.Context()
-	if len(rt.params) != 0 {
-		ctx = context.WithValue(ctx, ctxKey(paramsKey), rt.params)
+		params, ok := rt.match(r)
+		if !ok {
+			continue
+		}
+		ctx := r.Context()
+		if len(params) != 0 {
+			ctx = context.WithValue(ctx, ctxKey(paramsKey), params)
+		}
+		rt.handler.ServeHTTP(w, r.WithContext(ctx))
+		return
 	}
-	rt.handler.ServeHTTP(w, r.WithContext(ctx))
+	transport.SendError(w, models.NotFoundError{})
 }
 
 // getParams retrieves a map of url params for a given url
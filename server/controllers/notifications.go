@@ -0,0 +1,29 @@
+package controllers
+
+import (
+	"encoding/json"
+	"github.com/muhtutorials/reminders_cli/server/models"
+	"github.com/muhtutorials/reminders_cli/server/services"
+	"github.com/muhtutorials/reminders_cli/server/transport"
+	"net/http"
+)
+
+type notificationResolver interface {
+	Resolve(deliveryID string, result services.NotificationResult) error
+}
+
+func notificationResult(service notificationResolver) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		deliveryID := ctxParam(r.Context(), deliveryParamName).value
+		var body services.NotificationResult
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			transport.SendError(w, models.InvalidJSONError{Message: err.Error()})
+			return
+		}
+		if err := service.Resolve(deliveryID, body); err != nil {
+			transport.SendError(w, err)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})
+}
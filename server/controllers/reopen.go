@@ -0,0 +1,44 @@
+package controllers
+
+import (
+	"encoding/json"
+	"github.com/muhtutorials/reminders_cli/server/models"
+	"github.com/muhtutorials/reminders_cli/server/services"
+	"github.com/muhtutorials/reminders_cli/server/transport"
+	"io"
+	"net/http"
+	"time"
+)
+
+type reopener interface {
+	Reopen(body services.ReopenBody) (models.Reminder, error)
+}
+
+func reopenReminder(service reopener) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id, err := parseIDParam(r.Context())
+		if err != nil {
+			transport.SendError(w, err)
+			return
+		}
+		// the body is optional, the reminder keeps its due time without it
+		var body struct {
+			Duration time.Duration `json:"duration"`
+			DueAt    time.Time     `json:"due_at"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil && err != io.EOF {
+			transport.SendError(w, models.InvalidJSONError{Message: err.Error()})
+			return
+		}
+		reminder, err := service.Reopen(services.ReopenBody{
+			ID:       id,
+			Duration: body.Duration,
+			DueAt:    body.DueAt,
+		})
+		if err != nil {
+			transport.SendError(w, err)
+			return
+		}
+		transport.SendJSON(w, reminder, http.StatusOK)
+	})
+}
@@ -6,30 +6,62 @@ import (
 )
 
 const (
-	idParamName  = "id"
-	idsParamName = "ids"
-	idParam      = "{" + idParamName + "}:^[0-9]+$"
-	idsParam     = "{" + idsParamName + "}:[0-9]+(,[0-9]+)*"
+	idParamName       = "id"
+	idsParamName      = "ids"
+	deliveryParamName = "delivery"
+	webhookParamName  = "webhook"
+	idParam           = "{" + idParamName + "}:^[0-9]+$"
+	idsParam          = "{" + idsParamName + "}:[0-9]+(,[0-9]+)*"
+	deliveryParam     = "{" + deliveryParamName + "}:^[0-9a-f]+$"
+	webhookParam      = "{" + webhookParamName + "}:^[0-9a-f]+$"
 )
 
 type RemindersService interface {
 	creator
 	editor
 	fetcher
+	lister
+	searcher
 	deleter
+	canceller
+	completer
+	snoozer
+	reopener
+	tagCounter
 }
 
 type RouterConfig struct {
-	Service RemindersService
+	Service    RemindersService
+	Deliveries DeliveriesService
+	Webhooks   WebhooksService
+	DND        DNDService
+	Metrics    metricsReporter
+	Health     healthReporter
 }
 
 func NewRouter(cfg RouterConfig) http.Handler {
 	r := RegexMux{}
 	m := middleware.New(middleware.HTTPLogger)
+	r.Get("/reminders", m.Then(listReminders(cfg.Service)))
+	r.Get("/reminders/search", m.Then(searchReminders(cfg.Service)))
 	r.Get("/reminders/"+idsParam, m.Then(fetchReminders(cfg.Service)))
 	r.Post("/reminders", m.Then(createReminder(cfg.Service)))
 	r.Patch("/reminders/"+idParam, m.Then(editReminder(cfg.Service)))
 	r.Delete("/reminders/"+idsParam, m.Then(deleteReminders(cfg.Service)))
-	r.Get("/health", m.Then(health()))
+	r.Post("/reminders/"+idParam+"/cancel", m.Then(cancelReminder(cfg.Service)))
+	r.Post("/reminders/"+idParam+"/complete", m.Then(completeReminder(cfg.Service)))
+	r.Post("/reminders/"+idParam+"/snooze", m.Then(snoozeReminder(cfg.Service)))
+	r.Post("/reminders/"+idParam+"/reopen", m.Then(reopenReminder(cfg.Service)))
+	r.Get("/tags", m.Then(listTags(cfg.Service)))
+	r.Get("/deliveries", m.Then(listDeliveries(cfg.Deliveries)))
+	r.Post("/notifications/"+deliveryParam+"/result", m.Then(notificationResult(cfg.Deliveries)))
+	r.Get("/webhooks", m.Then(listWebhooks(cfg.Webhooks)))
+	r.Get("/webhooks/"+webhookParam, m.Then(fetchWebhook(cfg.Webhooks)))
+	r.Post("/webhooks", m.Then(createWebhook(cfg.Webhooks)))
+	r.Delete("/webhooks/"+webhookParam, m.Then(deleteWebhook(cfg.Webhooks)))
+	r.Get("/dnd", m.Then(getDND(cfg.DND)))
+	r.Post("/dnd", m.Then(setDND(cfg.DND)))
+	r.Get("/metrics", m.Then(metrics(cfg.Metrics)))
+	r.Get("/health", m.Then(health(cfg.Health)))
 	return r
 }
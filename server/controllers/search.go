@@ -0,0 +1,34 @@
+package controllers
+
+import (
+	"github.com/muhtutorials/reminders_cli/server/models"
+	"github.com/muhtutorials/reminders_cli/server/services"
+	"github.com/muhtutorials/reminders_cli/server/transport"
+	"net/http"
+	"strconv"
+)
+
+type searcher interface {
+	Search(q string, limit int) ([]services.SearchResult, error)
+}
+
+func searchReminders(service searcher) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		values := r.URL.Query()
+		limit := 0
+		if v := values.Get("limit"); v != "" {
+			n, err := strconv.Atoi(v)
+			if err != nil {
+				transport.SendError(w, models.DataValidationError{Message: "invalid limit provided"})
+				return
+			}
+			limit = n
+		}
+		results, err := service.Search(values.Get("q"), limit)
+		if err != nil {
+			transport.SendError(w, err)
+			return
+		}
+		transport.SendJSON(w, results, http.StatusOK)
+	})
+}
@@ -0,0 +1,42 @@
+package controllers
+
+import (
+	"encoding/json"
+	"github.com/muhtutorials/reminders_cli/server/models"
+	"github.com/muhtutorials/reminders_cli/server/services"
+	"github.com/muhtutorials/reminders_cli/server/transport"
+	"net/http"
+	"time"
+)
+
+type snoozer interface {
+	Snooze(body services.SnoozeBody) (models.Reminder, error)
+}
+
+func snoozeReminder(service snoozer) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id, err := parseIDParam(r.Context())
+		if err != nil {
+			transport.SendError(w, err)
+			return
+		}
+		var body struct {
+			Duration time.Duration `json:"duration"`
+			Until    time.Time     `json:"until"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			transport.SendError(w, models.InvalidJSONError{Message: err.Error()})
+			return
+		}
+		reminder, err := service.Snooze(services.SnoozeBody{
+			ID:       id,
+			Duration: body.Duration,
+			Until:    body.Until,
+		})
+		if err != nil {
+			transport.SendError(w, err)
+			return
+		}
+		transport.SendJSON(w, reminder, http.StatusOK)
+	})
+}
@@ -0,0 +1,32 @@
+package controllers
+
+import (
+	"github.com/muhtutorials/reminders_cli/server/services"
+	"github.com/muhtutorials/reminders_cli/server/transport"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+type tagCounter interface {
+	Tags() []services.TagCount
+}
+
+func listTags(service tagCounter) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		transport.SendJSON(w, service.Tags(), http.StatusOK)
+	})
+}
+
+// parseTagFilter parses the repeated or comma separated tag query param along with tag_match
+func parseTagFilter(values url.Values) services.TagFilter {
+	filter := services.TagFilter{Match: values.Get("tag_match")}
+	for _, tags := range values["tag"] {
+		for _, tag := range strings.Split(tags, ",") {
+			if tag != "" {
+				filter.Tags = append(filter.Tags, tag)
+			}
+		}
+	}
+	return filter
+}
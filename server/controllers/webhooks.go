@@ -0,0 +1,67 @@
+package controllers
+
+import (
+	"encoding/json"
+	"github.com/muhtutorials/reminders_cli/server/models"
+	"github.com/muhtutorials/reminders_cli/server/services"
+	"github.com/muhtutorials/reminders_cli/server/transport"
+	"net/http"
+)
+
+type WebhooksService interface {
+	Create(body services.WebhookCreateBody) (models.Webhook, error)
+	List() []models.Webhook
+	Fetch(id string) (models.Webhook, error)
+	Delete(id string) error
+}
+
+func createWebhook(service WebhooksService) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			URL    string   `json:"url"`
+			Events []string `json:"events"`
+			Secret string   `json:"secret"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			transport.SendError(w, models.InvalidJSONError{Message: err.Error()})
+			return
+		}
+		webhook, err := service.Create(services.WebhookCreateBody{
+			URL:    body.URL,
+			Events: body.Events,
+			Secret: body.Secret,
+		})
+		if err != nil {
+			transport.SendError(w, err)
+			return
+		}
+		transport.SendJSON(w, webhook, http.StatusCreated)
+	})
+}
+
+func listWebhooks(service WebhooksService) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		transport.SendJSON(w, service.List(), http.StatusOK)
+	})
+}
+
+func fetchWebhook(service WebhooksService) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		webhook, err := service.Fetch(ctxParam(r.Context(), webhookParamName).value)
+		if err != nil {
+			transport.SendError(w, err)
+			return
+		}
+		transport.SendJSON(w, webhook, http.StatusOK)
+	})
+}
+
+func deleteWebhook(service WebhooksService) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := service.Delete(ctxParam(r.Context(), webhookParamName).value); err != nil {
+			transport.SendError(w, err)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})
+}
@@ -0,0 +1,31 @@
+package models
+
+import "time"
+
+// Delivery represents a notification of a firing reminder kept in the outbox,
+// it is retried until the channel accepts it and then waits for the user action to be called back
+type Delivery struct {
+	ID            string         `json:"id"`
+	ReminderID    int            `json:"reminder_id"`
+	Status        DeliveryStatus `json:"status"`
+	Attempts      int            `json:"attempts"`
+	LastError     string         `json:"last_error,omitempty"`
+	CreatedAt     time.Time      `json:"created_at"`
+	NextAttemptAt *time.Time     `json:"next_attempt_at,omitempty"`
+	SentAt        *time.Time     `json:"sent_at,omitempty"`
+	ExpiresAt     *time.Time     `json:"expires_at,omitempty"`
+}
+
+// DeliveryStatus represents the outbox state of a delivery
+type DeliveryStatus string
+
+const (
+	// DeliveryQueued waits for its next attempt
+	DeliveryQueued DeliveryStatus = "queued"
+	// DeliverySending is being sent through its channel
+	DeliverySending DeliveryStatus = "sending"
+	// DeliveryAwaiting was accepted by its channel and waits for the user action
+	DeliveryAwaiting DeliveryStatus = "awaiting"
+	// DeliveryDead ran out of attempts or was rejected, it is kept for inspection only
+	DeliveryDead DeliveryStatus = "dead"
+)
@@ -53,6 +53,16 @@ func (e NotFoundError) Error() string {
 	return e.Message
 }
 
+// ConflictError represents the error returned when the request conflicts
+// with the current state of the resource
+type ConflictError struct {
+	Message string
+}
+
+func (e ConflictError) Error() string {
+	return e.Message
+}
+
 // WrapError wraps a plain error into a custom error
 func WrapError(customErr string, originalErr error) error {
 	err := fmt.Errorf("%s: %v", customErr, originalErr)
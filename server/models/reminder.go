@@ -2,12 +2,79 @@ package models
 
 import "time"
 
+// Reminder represents a reminder due at DueAt,
+// Duration is the relative time it was last scheduled with
+// and Missed is set when its current notification came due while the server was down,
+// QuietHours are the windows ("[days] HH:MM-HH:MM [time zone]") its notifications are held during,
+// TimeZone is the IANA time zone its due time and recurrence are evaluated in (the server one when empty),
+// Tags are the sorted lowercase labels categorizing it and Priority drives how it is notified (normal when empty)
 type Reminder struct {
 	ID          int           `json:"id"`
 	Title       string        `json:"title"`
 	Message     string        `json:"message"`
 	Duration    time.Duration `json:"duration"`
+	DueAt       time.Time     `json:"due_at"`
 	RetryPeriod time.Duration `json:"retry_period"`
+	Recurrence  *Recurrence   `json:"recurrence,omitempty"`
+	Actions     []Action      `json:"actions,omitempty"`
+	Channel     string        `json:"channel,omitempty"`
+	QuietHours  []string      `json:"quiet_hours,omitempty"`
+	TimeZone    string        `json:"time_zone,omitempty"`
+	Tags        []string      `json:"tags,omitempty"`
+	Priority    Priority      `json:"priority,omitempty"`
+	Status      Status        `json:"status"`
+	Missed      bool          `json:"missed,omitempty"`
+	FiredAt     *time.Time    `json:"fired_at,omitempty"`
+	CompletedAt *time.Time    `json:"completed_at,omitempty"`
 	CreatedAt   time.Time     `json:"created_at"`
 	ModifiedAt  time.Time     `json:"modified_at"`
 }
+
+// Status represents the lifecycle state of a reminder
+type Status string
+
+const (
+	StatusPending   Status = "pending"
+	StatusFiring    Status = "firing"
+	StatusSnoozed   Status = "snoozed"
+	StatusCompleted Status = "completed"
+	StatusCancelled Status = "cancelled"
+	StatusFailed    Status = "failed"
+)
+
+// Active checks whether a reminder with the status is still waiting to be notified or acknowledged
+func (s Status) Active() bool {
+	return s == StatusPending || s == StatusFiring || s == StatusSnoozed
+}
+
+// Priority represents how pressing a reminder is
+type Priority string
+
+const (
+	PriorityLow    Priority = "low"
+	PriorityNormal Priority = "normal"
+	PriorityHigh   Priority = "high"
+	PriorityUrgent Priority = "urgent"
+)
+
+// Recurrence represents the repeating schedule of a reminder,
+// Rule is an RRULE and Current is the due time of the current occurrence
+type Recurrence struct {
+	Rule       string    `json:"rule"`
+	Occurrence int       `json:"occurrence"`
+	Current    time.Time `json:"current"`
+}
+
+// Action represents a custom notification button and the effect clicking it has on the reminder
+type Action struct {
+	Label  string        `json:"label"`
+	Effect string        `json:"effect"`
+	Snooze time.Duration `json:"snooze,omitempty"`
+}
+
+const (
+	EffectComplete = "complete"
+	EffectSnooze   = "snooze"
+	EffectRetry    = "retry"
+	EffectCancel   = "cancel"
+)
@@ -0,0 +1,33 @@
+package models
+
+import "time"
+
+// Webhook represents a subscription of an external URL to reminder lifecycle events,
+// an empty Events list subscribes to all the events
+type Webhook struct {
+	ID         string            `json:"id"`
+	URL        string            `json:"url"`
+	Events     []string          `json:"events,omitempty"`
+	Secret     string            `json:"secret,omitempty"`
+	CreatedAt  time.Time         `json:"created_at"`
+	Deliveries []WebhookDelivery `json:"deliveries,omitempty"`
+}
+
+// WebhookDelivery represents a single event delivered to a webhook subscription and its attempts
+type WebhookDelivery struct {
+	ID            string    `json:"id"`
+	Event         string    `json:"event"`
+	ReminderID    int       `json:"reminder_id"`
+	Attempts      int       `json:"attempts"`
+	Status        string    `json:"status"`
+	StatusCode    int       `json:"status_code,omitempty"`
+	Error         string    `json:"error,omitempty"`
+	CreatedAt     time.Time `json:"created_at"`
+	LastAttemptAt time.Time `json:"last_attempt_at,omitempty"`
+}
+
+const (
+	WebhookDeliveryPending   = "pending"
+	WebhookDeliverySucceeded = "succeeded"
+	WebhookDeliveryFailed    = "failed"
+)
@@ -10,6 +10,7 @@ import (
 	"io"
 	"log"
 	"os"
+	"sync"
 )
 
 type dbConfig struct {
@@ -17,8 +18,10 @@ type dbConfig struct {
 	Checksum string `json:"checksum"`
 }
 
-// DB represents the application server database (json file)
+// DB represents the application server database (json file),
+// it is safe for concurrent use
 type DB struct {
+	mu        sync.Mutex
 	dbPath    string
 	dbCfgPath string
 	cfg       dbConfig
@@ -35,6 +38,8 @@ func NewDB(dbPath, dbCfgPath string) *DB {
 
 // Start starts and initializes the file database
 func (db *DB) Start() error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
 	bts, err := db.readDBFile(db.dbCfgPath)
 	if err != nil {
 		return models.WrapError("could not read db config contents", err)
@@ -79,6 +84,8 @@ func (db *DB) readDBFile(path string) ([]byte, error) {
 
 // Read fetches a list of reminders by given ids
 func (db *DB) Read(bts []byte) (int, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
 	n, err := bytes.NewReader(db.db).Read(bts)
 	if err != nil && err != io.EOF {
 		return 0, models.WrapError("could not read db file bytes", err)
@@ -88,6 +95,8 @@ func (db *DB) Read(bts []byte) (int, error) {
 
 // Write writes a list of reminders to DB
 func (db *DB) Write(bts []byte) (int, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
 	bts = append(bts, '\n')
 
 	checksum, err := genChecksum(bytes.NewReader(bts))
@@ -112,6 +121,8 @@ func (db *DB) Write(bts []byte) (int, error) {
 
 // Size retrieves the current size of the database
 func (db *DB) Size() int {
+	db.mu.Lock()
+	defer db.mu.Unlock()
 	if len(db.db) == 0 {
 		db.db = []byte("[]")
 	}
@@ -120,6 +131,8 @@ func (db *DB) Size() int {
 
 // GenerateID generates the next AUTOINCREMENT id for a reminder
 func (db *DB) GenerateID() int {
+	db.mu.Lock()
+	defer db.mu.Unlock()
 	db.cfg.ID++
 	return db.cfg.ID
 }
@@ -127,6 +140,8 @@ func (db *DB) GenerateID() int {
 // Stop shuts down properly the file database by saving metadata to config file
 func (db *DB) Stop() error {
 	log.Println("shutting down the database")
+	db.mu.Lock()
+	defer db.mu.Unlock()
 	_, errDB := os.Open(db.dbPath)
 	_, errDBCfg := os.Open(db.dbCfgPath)
 	if errors.Is(errDB, os.ErrNotExist) {
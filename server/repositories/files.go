@@ -0,0 +1,30 @@
+package repositories
+
+import (
+	"github.com/muhtutorials/reminders_cli/server/models"
+	"os"
+	"path/filepath"
+)
+
+// writeFileAtomic writes a file through a temporary file renamed over it,
+// so that a crash never leaves the file half written
+func writeFileAtomic(path string, bts []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return models.WrapError("could not create temporary file", err)
+	}
+	if _, err := tmp.Write(bts); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return models.WrapError("could not write temporary file", err)
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return models.WrapError("could not close temporary file", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		os.Remove(tmp.Name())
+		return models.WrapError("could not replace file", err)
+	}
+	return nil
+}
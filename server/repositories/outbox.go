@@ -0,0 +1,54 @@
+package repositories
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/muhtutorials/reminders_cli/server/models"
+	"os"
+	"sync"
+)
+
+// Outbox represents the notification deliveries repository,
+// the deliveries are kept in their own json file next to the reminders db
+type Outbox struct {
+	mu   sync.Mutex
+	path string
+}
+
+func NewOutbox(path string) *Outbox {
+	return &Outbox{
+		path: path,
+	}
+}
+
+// Load reads all the deliveries, a missing file has no deliveries
+func (o *Outbox) Load() ([]models.Delivery, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	bts, err := os.ReadFile(o.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, models.WrapError("could not read outbox file", err)
+	}
+	var deliveries []models.Delivery
+	if len(bts) == 0 {
+		return nil, nil
+	}
+	if err := json.Unmarshal(bts, &deliveries); err != nil {
+		return nil, models.WrapError("could not unmarshal outbox", err)
+	}
+	return deliveries, nil
+}
+
+// Save replaces all the deliveries, the file is written atomically
+func (o *Outbox) Save(deliveries []models.Delivery) error {
+	bts, err := json.Marshal(deliveries)
+	if err != nil {
+		return models.WrapError("could not marshal outbox", err)
+	}
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return writeFileAtomic(o.path, append(bts, '\n'))
+}
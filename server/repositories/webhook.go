@@ -0,0 +1,54 @@
+package repositories
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/muhtutorials/reminders_cli/server/models"
+	"os"
+	"sync"
+)
+
+// Webhooks represents the webhook subscriptions repository,
+// the subscriptions are kept in their own json file next to the reminders db
+type Webhooks struct {
+	mu   sync.Mutex
+	path string
+}
+
+func NewWebhooks(path string) *Webhooks {
+	return &Webhooks{
+		path: path,
+	}
+}
+
+// Load reads all the webhook subscriptions, a missing file has no subscriptions
+func (w *Webhooks) Load() ([]models.Webhook, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	bts, err := os.ReadFile(w.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, models.WrapError("could not read webhooks file", err)
+	}
+	var webhooks []models.Webhook
+	if len(bts) == 0 {
+		return nil, nil
+	}
+	if err := json.Unmarshal(bts, &webhooks); err != nil {
+		return nil, models.WrapError("could not unmarshal webhooks", err)
+	}
+	return webhooks, nil
+}
+
+// Save replaces all the webhook subscriptions, the file is written atomically
+func (w *Webhooks) Save(webhooks []models.Webhook) error {
+	bts, err := json.Marshal(webhooks)
+	if err != nil {
+		return models.WrapError("could not marshal webhooks", err)
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return writeFileAtomic(w.path, append(bts, '\n'))
+}
@@ -0,0 +1,105 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/muhtutorials/reminders_cli/server/models"
+	"strings"
+	"time"
+)
+
+const (
+	ActionDismissed = "dismissed"
+	ActionClicked   = "clicked"
+	ActionSnoozed   = "snoozed"
+	ActionTimeout   = "timeout"
+	ActionButton    = "button"
+)
+
+// NotificationResult represents the user action on a notification reported by the notifier service,
+// Snooze is set for snoozed notifications and Button holds the label of the clicked custom action
+type NotificationResult struct {
+	Action string        `json:"action"`
+	Snooze time.Duration `json:"snooze,omitempty"`
+	Button string        `json:"button,omitempty"`
+}
+
+// UnmarshalJSON accepts the snooze duration both in nanoseconds and as a duration string like "10m"
+func (r *NotificationResult) UnmarshalJSON(bts []byte) error {
+	var raw struct {
+		Action string          `json:"action"`
+		Snooze json.RawMessage `json:"snooze"`
+		Button string          `json:"button"`
+	}
+	if err := json.Unmarshal(bts, &raw); err != nil {
+		return err
+	}
+	r.Action, r.Button, r.Snooze = raw.Action, raw.Button, 0
+	if len(raw.Snooze) == 0 || string(raw.Snooze) == "null" {
+		return nil
+	}
+	var s string
+	if err := json.Unmarshal(raw.Snooze, &s); err == nil {
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return fmt.Errorf("invalid snooze duration '%s'", s)
+		}
+		r.Snooze = d
+		return nil
+	}
+	var ns int64
+	if err := json.Unmarshal(raw.Snooze, &ns); err != nil {
+		return fmt.Errorf("snooze must be either a duration string or nanoseconds")
+	}
+	r.Snooze = time.Duration(ns)
+	return nil
+}
+
+// validateActions validates the custom notification actions of a reminder
+func validateActions(actions []models.Action) error {
+	labels := map[string]bool{}
+	for _, action := range actions {
+		label := strings.TrimSpace(action.Label)
+		if label == "" {
+			return models.DataValidationError{Message: "action label cannot be empty"}
+		}
+		if labels[label] {
+			return models.DataValidationError{
+				Message: fmt.Sprintf("action label '%s' is duplicated", label),
+			}
+		}
+		labels[label] = true
+		switch action.Effect {
+		case models.EffectComplete, models.EffectRetry, models.EffectCancel:
+		case models.EffectSnooze:
+			if action.Snooze < 0 {
+				return models.DataValidationError{
+					Message: fmt.Sprintf("snooze of action '%s' cannot be negative", label),
+				}
+			}
+		default:
+			return models.DataValidationError{
+				Message: fmt.Sprintf("effect of action '%s' must be one of: complete, snooze, retry, cancel", label),
+			}
+		}
+	}
+	return nil
+}
+
+// effect maps a notification result to its effect on the reminder and the snooze duration,
+// unknown actions and buttons retry the reminder
+func effect(reminder models.Reminder, result NotificationResult) (string, time.Duration) {
+	switch result.Action {
+	case ActionDismissed, ActionClicked, ActionDelivered:
+		return models.EffectComplete, 0
+	case ActionSnoozed:
+		return models.EffectSnooze, result.Snooze
+	case ActionButton:
+		for _, action := range reminder.Actions {
+			if action.Label == result.Button {
+				return action.Effect, action.Snooze
+			}
+		}
+	}
+	return models.EffectRetry, 0
+}
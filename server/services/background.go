@@ -1,6 +1,7 @@
 package services
 
 import (
+	"errors"
 	"github.com/muhtutorials/reminders_cli/server/models"
 	"log"
 	"time"
@@ -12,13 +13,13 @@ type saver interface {
 
 // BackgroundSaver represents the reminder background saver
 type BackgroundSaver struct {
-	ticker  *time.Ticker
+	ticker  Ticker
 	done    chan struct{}
 	service saver
 }
 
-func NewSaver(service saver) *BackgroundSaver {
-	ticker := time.NewTicker(30 * time.Second)
+func NewSaver(service saver, clock Clock) *BackgroundSaver {
+	ticker := clock.NewTicker(30 * time.Second)
 	done := make(chan struct{})
 	return &BackgroundSaver{
 		ticker:  ticker,
@@ -31,7 +32,7 @@ func (s BackgroundSaver) Start() {
 	log.Println("background saver started")
 	for {
 		select {
-		case <-s.ticker.C:
+		case <-s.ticker.C():
 			err := s.service.save()
 			if err != nil {
 				log.Printf("could not save records in background: %v", err)
@@ -53,79 +54,242 @@ func (s BackgroundSaver) Stop() error {
 	return nil
 }
 
-// HTTPNotifierClient represents the HTTP client for communicating with the notifier server
-type HTTPNotifierClient interface {
-	Notify(reminder models.Reminder) (NotificationResponse, error)
-}
-
 type snapshotManager interface {
-	snapshot() Snapshot
+	active(id int) (models.Reminder, bool)
+	fire(id int) (models.Reminder, bool)
+	fired(id int) (models.Reminder, bool)
 	snapshotGrooming(notifiedReminder ...models.Reminder)
 	retry(reminder models.Reminder)
+	drop(id int)
+	fail(reminder models.Reminder)
+	snoozeFiring(reminder models.Reminder, d time.Duration)
+	cancelFiring(reminder models.Reminder)
 }
 
-// BackgroundNotifier represents the reminder background saver
+const (
+	// postponeDelay is the delay before a delivery which could not be submitted to the worker pool is attempted again
+	postponeDelay = time.Second
+	// maxSleep caps the time the notifier sleeps, so a clock jump is noticed even with nothing due soon
+	maxSleep = 30 * time.Second
+	// clockJumpThreshold is the drift between the wall clock and the monotonic clock reported as a clock jump
+	clockJumpThreshold = 2 * time.Second
+)
+
+// BackgroundNotifier represents the reminder background notifier,
+// it sleeps until the next reminder in the scheduler queue is due or the next outbox delivery attempt
+// and hands the deliveries over to a bounded pool of workers,
+// due times are compared on the wall clock so reminders which came due during a system suspend fire on resume
+// and reminders due during quiet hours or do-not-disturb are held until they end
 type BackgroundNotifier struct {
-	ticker    *time.Ticker
-	done      chan struct{}
-	service   snapshotManager
-	completed chan models.Reminder
-	Client    HTTPNotifierClient
+	scheduler  *Scheduler
+	deliveries *Deliveries
+	pool       *WorkerPool
+	quiet      *QuietHours
+	held       map[int]bool
+	done       chan struct{}
+	service    snapshotManager
+	channels   *Channels
+	clock      Clock
 }
 
-func NewNotifier(notifierURL string, service snapshotManager) *BackgroundNotifier {
-	ticker := time.NewTicker(time.Second)
+func NewNotifier(
+	channels *Channels,
+	service snapshotManager,
+	scheduler *Scheduler,
+	deliveries *Deliveries,
+	pool *WorkerPool,
+	quiet *QuietHours,
+	clock Clock,
+) *BackgroundNotifier {
 	done := make(chan struct{})
-	httpClient := NewHTTPClient(notifierURL)
 	return &BackgroundNotifier{
-		ticker:    ticker,
-		done:      done,
-		service:   service,
-		completed: make(chan models.Reminder),
-		Client:    httpClient,
+		scheduler:  scheduler,
+		deliveries: deliveries,
+		pool:       pool,
+		quiet:      quiet,
+		held:       map[int]bool{},
+		done:       done,
+		service:    service,
+		channels:   channels,
+		clock:      clock,
 	}
 }
 
 func (n BackgroundNotifier) Start() {
 	log.Println("background notifier started")
+	n.pool.Start(n.notify)
+	timer := n.clock.NewTimer(time.Hour)
+	defer timer.Stop()
+	last := n.clock.Now()
 	for {
-		select {
-		case <-n.ticker.C:
-			snapshot := n.service.snapshot()
-			for id := range snapshot.Uncompleted {
-				_, reminder := snapshot.Uncompleted.flatten(id)
-				reminderTick := reminder.ModifiedAt.Add(reminder.Duration).UnixNano()
-				nowTick := time.Now().UnixNano()
-				deltaTick := time.Now().Add(time.Second).UnixNano()
-				if reminderTick > nowTick && reminderTick < deltaTick {
-					go n.notify(reminder)
-				}
+		// the monotonic reading is stripped since it stops while the system is suspended
+		now := n.clock.Now().Round(0)
+		for _, id := range n.scheduler.Due(now) {
+			delete(n.held, id)
+			if n.hold(id, now) {
+				continue
+			}
+			reminder, ok := n.service.fire(id)
+			if ok {
+				n.deliveries.enqueue(reminder)
 			}
-		case r := <-n.completed:
-			log.Printf("reminder with: %d was completed\n", r.ID)
+		}
+		for _, delivery := range n.deliveries.due(n.clock.Now().Round(0)) {
+			n.submit(delivery)
+		}
+
+		sleep := maxSleep
+		at, ok := n.scheduler.Next()
+		if retryAt, retry := n.deliveries.next(); retry && (!ok || retryAt.Before(at)) {
+			at, ok = retryAt, true
+		}
+		if until := at.Round(0).Sub(n.clock.Now().Round(0)); ok && until < sleep {
+			sleep = until
+		}
+		resetTimer(timer, sleep)
+
+		select {
+		case <-timer.C():
+		case <-n.scheduler.Wake():
+		case <-n.deliveries.Wake():
+		case <-n.quiet.Wake():
+			n.release()
 		case <-n.done:
 			return
 		}
+
+		now = n.clock.Now()
+		if jump := clockJump(last, now); jump != 0 {
+			// the due reminders are checked against the new wall clock on the next iteration
+			// and the held ones are checked against the quiet hours again
+			log.Printf("wall clock jumped by %v (system suspend or clock change), re-evaluating %d scheduled and %d held reminder(s)",
+				jump, n.scheduler.Len(), len(n.held))
+			n.release()
+		}
+		last = now
+	}
+}
+
+// hold holds a due reminder while quiet hours or do-not-disturb are in effect,
+// it is scheduled again when they end or released when do-not-disturb is toggled,
+// low-priority reminders are dropped instead when the quiet hours drop them
+func (n BackgroundNotifier) hold(id int, now time.Time) bool {
+	reminder, ok := n.service.active(id)
+	if !ok {
+		return false
+	}
+	until, quiet := n.quiet.reminderUntil(reminder, now)
+	if !quiet {
+		return false
+	}
+	if n.quiet.drops(reminder) {
+		n.service.drop(id)
+		return true
+	}
+	n.held[id] = true
+	if until.IsZero() {
+		log.Printf("reminder with id: %d is held until do not disturb is disabled", id)
+		return true
+	}
+	log.Printf("reminder with id: %d is held by quiet hours or do not disturb until %v", id, until.Format(time.RFC3339))
+	n.scheduler.Schedule(id, until)
+	return true
+}
+
+// release schedules the held reminders right away to evaluate them again,
+// reminders rescheduled in the meantime are left alone
+func (n BackgroundNotifier) release() {
+	now := n.clock.Now()
+	for id := range n.held {
+		delete(n.held, id)
+		if reminder, ok := n.service.active(id); ok && !reminder.DueAt.After(now) {
+			n.scheduler.Schedule(id, now)
+		}
 	}
 }
 
 func (n BackgroundNotifier) Stop() error {
-	n.ticker.Stop()
 	n.done <- struct{}{}
+	n.pool.Stop(5 * time.Second)
+	n.channels.stop()
 	log.Println("background notifier stopped")
 	return nil
 }
 
-// notify notifies a reminder via the HTTP client
-func (n BackgroundNotifier) notify(r models.Reminder) {
-	res, err := n.Client.Notify(r)
+// submit hands a delivery over to the worker pool, the delivery is postponed while the pool is full
+// and dropped if its reminder is already being notified
+func (n BackgroundNotifier) submit(delivery models.Delivery) {
+	switch err := n.pool.Submit(delivery); {
+	case errors.Is(err, errInFlight):
+		log.Printf("dropping delivery %s: reminder with id %d is already being notified", delivery.ID, delivery.ReminderID)
+		n.deliveries.drop(delivery.ID)
+	case errors.Is(err, errPoolFull):
+		stats := n.pool.Stats()
+		log.Printf("postponing delivery %s: %v (%d queued, %d rejected so far)",
+			delivery.ID, err, stats.Queued, stats.Rejected)
+		n.deliveries.postpone(delivery.ID, postponeDelay)
+	}
+}
+
+// notify attempts an outbox delivery through the channel of its reminder,
+// the delivery result is resolved once the channel calls back or right away
+func (n BackgroundNotifier) notify(delivery models.Delivery) {
+	r, ok := n.service.fired(delivery.ReminderID)
+	if !ok {
+		// the reminder was completed, cancelled or deleted in the meantime
+		n.deliveries.drop(delivery.ID)
+		return
+	}
+	name, channel, err := n.channels.resolve(r)
+	var res NotificationResponse
+	if err == nil {
+		res, err = channel.Notify(delivery, r)
+	}
+	var circuitOpen CircuitOpenError
+	if errors.As(err, &circuitOpen) {
+		// the delivery is held without counting the attempt until the channel is probed again
+		n.deliveries.postpone(delivery.ID, circuitOpen.RetryAt.Sub(n.clock.Now()))
+		return
+	}
+	if errors.Is(err, errNotificationRejected) {
+		log.Printf("%s channel rejected reminder with id %d: %v\n", name, r.ID, err)
+		n.deliveries.kill(delivery.ID, err)
+		n.service.fail(r)
+		return
+	}
 	if err != nil {
 		log.Printf("could not notify reminder with id %d\n", r.ID)
-		log.Printf("background http client error: %v\n", err)
-	} else if res.completed {
-		n.service.snapshotGrooming(r)
-		n.completed <- r
+		log.Printf("background %s channel error: %v\n", name, err)
+		if !n.deliveries.retry(delivery.ID, err) {
+			n.service.fail(r)
+		}
+		return
+	}
+	if res.pending {
+		n.deliveries.await(delivery.ID)
 		return
 	}
-	n.service.retry(r)
+	// the channel replied with the user action right away
+	_ = n.deliveries.Resolve(delivery.ID, res.result)
+}
+
+// clockJump retrieves how far the wall clock moved apart from the monotonic clock between two readings,
+// zero is returned for a drift below clockJumpThreshold
+func clockJump(last, now time.Time) time.Duration {
+	jump := now.Round(0).Sub(last.Round(0)) - now.Sub(last)
+	if jump < clockJumpThreshold && jump > -clockJumpThreshold {
+		return 0
+	}
+	return jump.Round(time.Second)
+}
+
+// resetTimer safely resets a timer which might have already fired
+func resetTimer(t Timer, d time.Duration) {
+	if !t.Stop() {
+		select {
+		case <-t.C():
+		default:
+		}
+	}
+	t.Reset(d)
 }
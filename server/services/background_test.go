@@ -0,0 +1,204 @@
+package services
+
+import (
+	"github.com/muhtutorials/reminders_cli/server/models"
+	"runtime"
+	"sync"
+	"testing"
+	"time"
+)
+
+// memoryRepository represents an in memory reminder repository
+type memoryRepository struct {
+	mu        sync.Mutex
+	lastID    int
+	reminders []models.Reminder
+}
+
+func (r *memoryRepository) Save(reminders []models.Reminder) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.reminders = reminders
+	return len(reminders), nil
+}
+
+func (r *memoryRepository) Filter(filterFn func(reminder models.Reminder) bool) (RemindersMap, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	reminders := RemindersMap{}
+	for i, reminder := range r.reminders {
+		if filterFn == nil || filterFn(reminder) {
+			reminders[reminder.ID] = map[int]models.Reminder{i: reminder}
+		}
+	}
+	return reminders, nil
+}
+
+func (r *memoryRepository) NextID() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.lastID++
+	return r.lastID
+}
+
+// memoryOutbox represents an in memory delivery repository
+type memoryOutbox struct {
+	mu         sync.Mutex
+	deliveries []models.Delivery
+}
+
+func (o *memoryOutbox) Load() ([]models.Delivery, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return o.deliveries, nil
+}
+
+func (o *memoryOutbox) Save(deliveries []models.Delivery) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.deliveries = deliveries
+	return nil
+}
+
+// scriptedChannel represents a channel replying to every notification with the next scripted user action,
+// or failing with the next scripted error when it is not nil
+type scriptedChannel struct {
+	mu       sync.Mutex
+	actions  []string
+	errs     []error
+	notified []time.Time
+	clock    Clock
+}
+
+func (c *scriptedChannel) Notify(delivery models.Delivery, reminder models.Reminder) (NotificationResponse, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	n := len(c.notified)
+	c.notified = append(c.notified, c.clock.Now())
+	if n < len(c.errs) && c.errs[n] != nil {
+		return NotificationResponse{}, c.errs[n]
+	}
+	action := ActionTimeout
+	if n < len(c.actions) {
+		action = c.actions[n]
+	}
+	return NotificationResponse{result: NotificationResult{Action: action}}, nil
+}
+
+func (c *scriptedChannel) notifications() []time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]time.Time(nil), c.notified...)
+}
+
+// waitArmed waits until a timer is armed at the given deadline
+func waitArmed(t *testing.T, clock *FakeClock, deadline time.Time) {
+	t.Helper()
+	for {
+		select {
+		case at := <-clock.Armed():
+			if at.Equal(deadline) {
+				return
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("no timer was armed at %v", deadline)
+		}
+	}
+}
+
+// runUntil moves the clock from one armed deadline to the next until cond holds,
+// the clock only moves once the goroutines went back to sleep on it and the pool workers are idle,
+// and never past limit
+func runUntil(t *testing.T, clock *FakeClock, pool *WorkerPool, limit time.Time, cond func() bool) {
+	t.Helper()
+	for !cond() {
+		select {
+		case at := <-clock.Armed():
+			if at.After(limit) {
+				t.Fatalf("condition not met by %v", limit)
+			}
+			waitIdle(t, pool)
+			if now := clock.Now(); at.Before(now) {
+				at = now
+			}
+			clock.Set(at)
+		case <-time.After(5 * time.Second):
+			t.Fatalf("condition not met and no timer was armed at %v", clock.Now())
+		}
+	}
+}
+
+// waitIdle waits until the pool workers handled every submitted delivery,
+// the workers do not sleep on the clock so they are given a chance to run instead
+func waitIdle(t *testing.T, pool *WorkerPool) {
+	t.Helper()
+	watchdog := time.After(5 * time.Second)
+	for {
+		if stats := pool.Stats(); stats.Queued == 0 && stats.InFlight == 0 {
+			return
+		}
+		select {
+		case <-watchdog:
+			t.Fatal("notification workers did not finish")
+		default:
+			runtime.Gosched()
+		}
+	}
+}
+
+func TestBackgroundNotifierRetriesUntilCompleted(t *testing.T) {
+	start := time.Date(2030, 1, 1, 9, 0, 0, 0, time.UTC)
+	clock := NewFakeClock(start)
+	channel := &scriptedChannel{actions: []string{ActionTimeout, ActionDismissed}, clock: clock}
+	channels := NewChannels("scripted")
+	channels.Register("scripted", channel)
+	scheduler := NewScheduler()
+	rs := NewReminders(&memoryRepository{}, scheduler, channels, nil, CatchUpFire, clock)
+	deliveries := NewDeliveries(&memoryOutbox{}, rs, time.Minute, Backoff{Attempts: 3, Base: time.Second, Max: time.Minute}, clock)
+	quiet := NewQuietHours(nil, false, clock)
+	pool := NewWorkerPool(1, 10, clock)
+	notifier := NewNotifier(channels, rs, scheduler, deliveries, pool, quiet, clock)
+	go notifier.Start()
+	defer notifier.Stop()
+	// the notifier sleeps as long as it can with nothing scheduled
+	waitArmed(t, clock, start.Add(maxSleep))
+
+	reminder, err := rs.Create(ReminderCreateBody{
+		Title:       "stand up",
+		Message:     "daily meeting",
+		Duration:    time.Minute,
+		RetryPeriod: 5 * time.Minute,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	status := func() models.Status {
+		rs.mu.RLock()
+		defer rs.mu.RUnlock()
+		_, r := rs.state.All.flatten(reminder.ID)
+		return r.Status
+	}
+
+	// the first notification times out and the reminder waits for the retry period
+	runUntil(t, clock, pool, start.Add(time.Hour), func() bool {
+		return len(channel.notifications()) == 1 && scheduler.Len() == 1
+	})
+	if got := status(); got != models.StatusFiring {
+		t.Fatalf("got status %s after the first notification, want firing", got)
+	}
+	if at := channel.notifications()[0]; at.Before(start.Add(time.Minute)) {
+		t.Fatalf("reminder notified at %v, before it was due", at)
+	}
+
+	// the retry is dismissed which completes the reminder
+	runUntil(t, clock, pool, start.Add(time.Hour), func() bool {
+		return status() == models.StatusCompleted
+	})
+	notified := channel.notifications()
+	if len(notified) != 2 {
+		t.Fatalf("got %d notifications, want 2", len(notified))
+	}
+	if gap := notified[1].Sub(notified[0]); gap < 5*time.Minute {
+		t.Fatalf("reminder retried after %v, want at least the 5m retry period", gap)
+	}
+}
@@ -0,0 +1,158 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"github.com/muhtutorials/reminders_cli/server/models"
+	"log"
+	"sync"
+	"time"
+)
+
+const (
+	BreakerClosed = "closed"
+	BreakerOpen   = "open"
+)
+
+// CircuitOpenError is returned for the deliveries held while a circuit breaker is open,
+// they should be attempted again once the breaker probes its channel at RetryAt
+type CircuitOpenError struct {
+	Channel string
+	RetryAt time.Time
+}
+
+func (e CircuitOpenError) Error() string {
+	return fmt.Sprintf("circuit breaker of %s channel is open until %s", e.Channel, e.RetryAt.Format(time.RFC3339))
+}
+
+// BreakerState represents the state of a circuit breaker
+type BreakerState struct {
+	State     string     `json:"state"`
+	Failures  int        `json:"failures"`
+	OpenedAt  *time.Time `json:"opened_at,omitempty"`
+	LastError string     `json:"last_error,omitempty"`
+}
+
+// CircuitBreaker wraps a channel and stops calling it after threshold consecutive failures,
+// while open it probes the channel every interval and closes again once a probe succeeds or the breaker is stopped
+type CircuitBreaker struct {
+	mu        sync.Mutex
+	name      string
+	channel   Channel
+	probe     func() error
+	threshold int
+	interval  time.Duration
+	clock     Clock
+	state     BreakerState
+	nextProbe time.Time
+	stopped   bool
+	done      chan struct{}
+}
+
+func NewCircuitBreaker(
+	name string,
+	channel Channel,
+	probe func() error,
+	threshold int,
+	interval time.Duration,
+	clock Clock,
+) *CircuitBreaker {
+	return &CircuitBreaker{
+		name:      name,
+		channel:   channel,
+		probe:     probe,
+		threshold: threshold,
+		interval:  interval,
+		clock:     clock,
+		state:     BreakerState{State: BreakerClosed},
+		done:      make(chan struct{}),
+	}
+}
+
+// Notify notifies through the wrapped channel unless the breaker is open,
+// rejected notifications don't count as failures since the channel itself is up
+func (b *CircuitBreaker) Notify(delivery models.Delivery, reminder models.Reminder) (NotificationResponse, error) {
+	b.mu.Lock()
+	if b.state.State == BreakerOpen {
+		err := CircuitOpenError{Channel: b.name, RetryAt: b.nextProbe}
+		b.mu.Unlock()
+		return NotificationResponse{}, err
+	}
+	b.mu.Unlock()
+
+	res, err := b.channel.Notify(delivery, reminder)
+	if err != nil && !errors.Is(err, errNotificationRejected) {
+		b.failure(err)
+	} else {
+		b.success()
+	}
+	return res, err
+}
+
+// State retrieves the current state of the breaker
+func (b *CircuitBreaker) State() BreakerState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.state
+}
+
+// Stop stops probing the channel, the breaker does not probe it again even if it opens afterwards
+func (b *CircuitBreaker) Stop() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if !b.stopped {
+		b.stopped = true
+		close(b.done)
+	}
+}
+
+// success resets the consecutive failures
+func (b *CircuitBreaker) success() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.state.Failures = 0
+	b.state.LastError = ""
+}
+
+// failure counts a failure and opens the breaker once the threshold is reached
+func (b *CircuitBreaker) failure(err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.state.Failures++
+	b.state.LastError = err.Error()
+	if b.state.State == BreakerOpen || b.state.Failures < b.threshold {
+		return
+	}
+	now := b.clock.Now()
+	b.state.State = BreakerOpen
+	b.state.OpenedAt = &now
+	b.nextProbe = now.Add(b.interval)
+	log.Printf("circuit breaker of %s channel opened after %d consecutive failure(s): %v", b.name, b.state.Failures, err)
+	if !b.stopped {
+		go b.probing()
+	}
+}
+
+// probing probes the channel until it is healthy again and closes the breaker
+func (b *CircuitBreaker) probing() {
+	ticker := b.clock.NewTicker(b.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C():
+		case <-b.done:
+			return
+		}
+		err := b.probe()
+		b.mu.Lock()
+		if err == nil {
+			b.state = BreakerState{State: BreakerClosed}
+			b.mu.Unlock()
+			log.Printf("circuit breaker of %s channel closed, the channel is healthy again", b.name)
+			return
+		}
+		b.state.LastError = err.Error()
+		b.nextProbe = b.clock.Now().Add(b.interval)
+		b.mu.Unlock()
+	}
+}
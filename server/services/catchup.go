@@ -0,0 +1,99 @@
+package services
+
+import (
+	"fmt"
+	"github.com/muhtutorials/reminders_cli/server/models"
+	"log"
+	"sort"
+	"strings"
+	"time"
+)
+
+// CatchUpPolicy decides what happens at startup to the active reminders which came due while the server was down
+type CatchUpPolicy string
+
+const (
+	// CatchUpFire fires every missed reminder right away, recurring reminders fire each missed occurrence in turn
+	CatchUpFire CatchUpPolicy = "fire"
+	// CatchUpLatest fires every missed reminder right away, recurring reminders only fire their latest missed occurrence
+	CatchUpLatest CatchUpPolicy = "latest"
+	// CatchUpMark only marks the missed reminders, they are not notified until they are rescheduled
+	CatchUpMark CatchUpPolicy = "mark"
+	// CatchUpCoalesce marks the missed reminders and notifies a single summary reminder listing them
+	CatchUpCoalesce CatchUpPolicy = "coalesce"
+
+	summaryTitle       = "Missed reminders"
+	summaryRetryPeriod = 5 * time.Minute
+)
+
+// ParseCatchUpPolicy parses a catch-up policy name
+func ParseCatchUpPolicy(s string) (CatchUpPolicy, error) {
+	switch policy := CatchUpPolicy(strings.ToLower(s)); policy {
+	case CatchUpFire, CatchUpLatest, CatchUpMark, CatchUpCoalesce:
+		return policy, nil
+	}
+	return "", fmt.Errorf("invalid catch-up policy '%s', expected fire, latest, mark or coalesce", s)
+}
+
+// indexedReminder represents a reminder along with its index in the snapshot
+type indexedReminder struct {
+	index    int
+	reminder models.Reminder
+}
+
+// catchUp applies the catch-up policy to the reminders which came due while the server was down,
+// reminders already marked as missed by a previous startup are not summarized again
+func (rs *Reminders) catchUp(missed []indexedReminder, now time.Time) {
+	if len(missed) == 0 {
+		return
+	}
+	log.Printf("%d reminder(s) came due while the server was down, catching up with policy: %s", len(missed), rs.catchUpPolicy)
+	var fresh []models.Reminder
+	for _, m := range missed {
+		reminder := m.reminder
+		if !reminder.Missed {
+			fresh = append(fresh, reminder)
+		}
+		reminder.Missed = true
+		switch rs.catchUpPolicy {
+		case CatchUpFire:
+			rs.scheduler.Schedule(reminder.ID, reminder.DueAt)
+		case CatchUpLatest:
+			reminder = latestOccurrence(reminder, now)
+			rs.scheduler.Schedule(reminder.ID, reminder.DueAt)
+		}
+		rs.store(m.index, reminder)
+	}
+	if rs.catchUpPolicy == CatchUpCoalesce && len(fresh) > 0 {
+		rs.summarize(fresh, now)
+	}
+}
+
+// summarize creates a reminder due right away listing the missed reminders
+func (rs *Reminders) summarize(missed []models.Reminder, now time.Time) {
+	sort.Slice(missed, func(i, j int) bool {
+		return missed[i].ID < missed[j].ID
+	})
+	titles := make([]string, len(missed))
+	retryPeriod := summaryRetryPeriod
+	for i, reminder := range missed {
+		titles[i] = fmt.Sprintf("%s (#%d)", reminder.Title, reminder.ID)
+		if reminder.RetryPeriod > 0 && reminder.RetryPeriod < retryPeriod {
+			retryPeriod = reminder.RetryPeriod
+		}
+	}
+	summary := models.Reminder{
+		ID:          rs.repo.NextID(),
+		Title:       summaryTitle,
+		Message:     fmt.Sprintf("%d reminder(s) came due while the server was down: %s", len(missed), strings.Join(titles, ", ")),
+		DueAt:       now,
+		RetryPeriod: retryPeriod,
+		Status:      models.StatusPending,
+		CreatedAt:   now,
+		ModifiedAt:  now,
+	}
+	rs.lastIndex++
+	rs.store(rs.lastIndex, summary)
+	rs.scheduler.Schedule(summary.ID, summary.DueAt)
+	rs.publish(EventReminderCreated, summary)
+}
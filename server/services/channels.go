@@ -0,0 +1,136 @@
+package services
+
+import (
+	"fmt"
+	"github.com/muhtutorials/reminders_cli/server/models"
+	"sort"
+	"strings"
+)
+
+const (
+	ChannelNotifier = "notifier"
+	ChannelWebhook  = "webhook"
+	ChannelCommand  = "command"
+	ChannelSink     = "sink"
+	ChannelEmail    = "email"
+
+	// ChannelDefault resets the channel of an edited reminder to the server default
+	ChannelDefault = "default"
+
+	// ActionDelivered is reported by the channels without user interaction, it completes the reminder
+	ActionDelivered = "delivered"
+)
+
+// Channel represents a way of delivering reminder notifications,
+// a pending response means the user action is called back later for the delivery
+type Channel interface {
+	Notify(delivery models.Delivery, reminder models.Reminder) (NotificationResponse, error)
+}
+
+// Channels represents the registry of the notification channels configured on the server,
+// reminders are delivered through their own channel, the channel routed for their priority or the default one
+type Channels struct {
+	channels   map[string]Channel
+	priorities map[models.Priority]string
+	fallback   string
+}
+
+func NewChannels(fallback string) *Channels {
+	return &Channels{
+		channels:   map[string]Channel{},
+		priorities: map[models.Priority]string{},
+		fallback:   fallback,
+	}
+}
+
+// Route delivers the reminders with the given priority and no channel of their own through the named channel
+func (c *Channels) Route(priority models.Priority, name string) {
+	c.priorities[priority] = name
+}
+
+// Register registers a channel under the given name, replacing any channel with the same name
+func (c *Channels) Register(name string, channel Channel) {
+	c.channels[name] = channel
+}
+
+// Validate checks that the default channel and the channels routed by priority are registered
+func (c *Channels) Validate() error {
+	if _, ok := c.channels[c.fallback]; !ok {
+		return fmt.Errorf("default channel '%s' is not configured, available: %s", c.fallback, c.names())
+	}
+	for priority, name := range c.priorities {
+		if _, ok := c.channels[name]; !ok {
+			return fmt.Errorf("channel '%s' of %s priority is not configured, available: %s", name, priority, c.names())
+		}
+	}
+	return nil
+}
+
+// has checks whether a channel is registered under the given name
+func (c *Channels) has(name string) bool {
+	_, ok := c.channels[name]
+	return ok
+}
+
+// resolve retrieves the channel a reminder is delivered through
+func (c *Channels) resolve(reminder models.Reminder) (string, Channel, error) {
+	name := reminder.Channel
+	if name == "" {
+		name = c.priorities[reminderPriority(reminder)]
+	}
+	if name == "" {
+		name = c.fallback
+	}
+	channel, ok := c.channels[name]
+	if !ok {
+		return name, nil, fmt.Errorf("%w: channel '%s' is not configured", errNotificationRejected, name)
+	}
+	return name, channel, nil
+}
+
+// Breakers retrieves the states of the circuit breakers of the channels which have one
+func (c *Channels) Breakers() map[string]BreakerState {
+	breakers := map[string]BreakerState{}
+	for name, channel := range c.channels {
+		switch channel := channel.(type) {
+		case *CircuitBreaker:
+			breakers[name] = channel.State()
+		case *NotifierRouter:
+			for target, state := range channel.breakers() {
+				breakers[target] = state
+			}
+		}
+	}
+	return breakers
+}
+
+// stop stops the circuit breakers of the channels which have one
+func (c *Channels) stop() {
+	for _, channel := range c.channels {
+		switch channel := channel.(type) {
+		case *CircuitBreaker:
+			channel.Stop()
+		case *NotifierRouter:
+			channel.stop()
+		}
+	}
+}
+
+func (c *Channels) names() string {
+	var names []string
+	for name := range c.channels {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return strings.Join(names, ", ")
+}
+
+// validateChannel validates the channel requested for a reminder
+func validateChannel(channels *Channels, name string) error {
+	if name == "" || channels.has(name) {
+		return nil
+	}
+	return models.DataValidationError{
+		Message: fmt.Sprintf("channel '%s' is not configured, available: %s", name, channels.names()),
+	}
+}
@@ -0,0 +1,237 @@
+package services
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// Clock represents the time source of the scheduling services,
+// RealClock is used in production and FakeClock runs them in virtual time
+type Clock interface {
+	Now() time.Time
+	NewTimer(d time.Duration) Timer
+	NewTicker(d time.Duration) Ticker
+	AfterFunc(d time.Duration, f func()) Timer
+}
+
+// Timer represents a single event created by a Clock, timers created by AfterFunc have a nil channel
+type Timer interface {
+	C() <-chan time.Time
+	Stop() bool
+	Reset(d time.Duration) bool
+}
+
+// Ticker represents a periodic event created by a Clock
+type Ticker interface {
+	C() <-chan time.Time
+	Stop()
+}
+
+// RealClock represents the clock of the time package
+type RealClock struct{}
+
+func NewRealClock() RealClock {
+	return RealClock{}
+}
+
+func (RealClock) Now() time.Time {
+	return time.Now()
+}
+
+func (RealClock) NewTimer(d time.Duration) Timer {
+	return realTimer{timer: time.NewTimer(d)}
+}
+
+func (RealClock) NewTicker(d time.Duration) Ticker {
+	return realTicker{ticker: time.NewTicker(d)}
+}
+
+func (RealClock) AfterFunc(d time.Duration, f func()) Timer {
+	return realTimer{timer: time.AfterFunc(d, f)}
+}
+
+type realTimer struct {
+	timer *time.Timer
+}
+
+func (t realTimer) C() <-chan time.Time {
+	return t.timer.C
+}
+
+func (t realTimer) Stop() bool {
+	return t.timer.Stop()
+}
+
+func (t realTimer) Reset(d time.Duration) bool {
+	return t.timer.Reset(d)
+}
+
+type realTicker struct {
+	ticker *time.Ticker
+}
+
+func (t realTicker) C() <-chan time.Time {
+	return t.ticker.C
+}
+
+func (t realTicker) Stop() {
+	t.ticker.Stop()
+}
+
+// fakeArmedBuffer bounds the deadlines reported by FakeClock.Armed which were not received yet
+const fakeArmedBuffer = 64
+
+// FakeClock represents a clock which only moves when it is advanced,
+// the timers, tickers and functions due by the new time are fired in order by Advance and Set
+type FakeClock struct {
+	mu      sync.Mutex
+	now     time.Time
+	waiters []*fakeWaiter
+	armed   chan time.Time
+}
+
+func NewFakeClock(now time.Time) *FakeClock {
+	return &FakeClock{
+		now:   now,
+		armed: make(chan time.Time, fakeArmedBuffer),
+	}
+}
+
+func (c *FakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *FakeClock) NewTimer(d time.Duration) Timer {
+	return c.add(&fakeWaiter{clock: c, c: make(chan time.Time, 1)}, d)
+}
+
+func (c *FakeClock) NewTicker(d time.Duration) Ticker {
+	if d <= 0 {
+		panic("non-positive interval for FakeClock.NewTicker")
+	}
+	return fakeTicker{c.add(&fakeWaiter{clock: c, c: make(chan time.Time, 1), period: d}, d)}
+}
+
+func (c *FakeClock) AfterFunc(d time.Duration, f func()) Timer {
+	return c.add(&fakeWaiter{clock: c, fn: f}, d)
+}
+
+// Advance moves the clock forward by d
+func (c *FakeClock) Advance(d time.Duration) {
+	c.Set(c.Now().Add(d))
+}
+
+// Set moves the clock to t, which may also be in the past to simulate a wall clock jump,
+// functions of the due AfterFunc timers run synchronously before Set returns
+func (c *FakeClock) Set(t time.Time) {
+	c.mu.Lock()
+	c.now = t
+	var due []*fakeWaiter
+	for _, w := range c.waiters {
+		if !w.at.After(t) {
+			due = append(due, w)
+		}
+	}
+	sort.SliceStable(due, func(i, j int) bool {
+		return due[i].at.Before(due[j].at)
+	})
+	var fns []func()
+	for _, w := range due {
+		if w.fn != nil {
+			fns = append(fns, w.fn)
+		} else {
+			select {
+			case w.c <- t:
+			default:
+			}
+		}
+		if w.period > 0 {
+			// like a real ticker, the ticks missed by a large step are dropped
+			for !w.at.After(t) {
+				w.at = w.at.Add(w.period)
+			}
+			continue
+		}
+		c.remove(w)
+	}
+	c.mu.Unlock()
+
+	for _, fn := range fns {
+		fn()
+	}
+}
+
+// Armed retrieves a channel receiving the deadline of every timer, ticker or function armed or reset,
+// tests can wait on it to know when a goroutine went back to sleep on the clock,
+// deadlines are dropped while fakeArmedBuffer of them are waiting to be received
+func (c *FakeClock) Armed() <-chan time.Time {
+	return c.armed
+}
+
+// add arms a new waiter to fire after d
+func (c *FakeClock) add(w *fakeWaiter, d time.Duration) *fakeWaiter {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.arm(w, d)
+	return w
+}
+
+// arm arms a waiter to fire after d and reports its deadline, it must be called with mu held
+func (c *FakeClock) arm(w *fakeWaiter, d time.Duration) {
+	w.at = c.now.Add(d)
+	c.waiters = append(c.waiters, w)
+	select {
+	case c.armed <- w.at:
+	default:
+	}
+}
+
+// remove disarms a waiter and reports whether it was active
+func (c *FakeClock) remove(w *fakeWaiter) bool {
+	for i, other := range c.waiters {
+		if other == w {
+			c.waiters = append(c.waiters[:i], c.waiters[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// fakeWaiter represents a timer, a ticker (with a period) or a function (with fn) of a FakeClock
+type fakeWaiter struct {
+	clock  *FakeClock
+	at     time.Time
+	period time.Duration
+	fn     func()
+	c      chan time.Time
+}
+
+func (w *fakeWaiter) C() <-chan time.Time {
+	return w.c
+}
+
+func (w *fakeWaiter) Stop() bool {
+	w.clock.mu.Lock()
+	defer w.clock.mu.Unlock()
+	return w.clock.remove(w)
+}
+
+func (w *fakeWaiter) Reset(d time.Duration) bool {
+	w.clock.mu.Lock()
+	defer w.clock.mu.Unlock()
+	active := w.clock.remove(w)
+	w.clock.arm(w, d)
+	return active
+}
+
+// fakeTicker represents a periodic fakeWaiter
+type fakeTicker struct {
+	*fakeWaiter
+}
+
+func (t fakeTicker) Stop() {
+	t.fakeWaiter.Stop()
+}
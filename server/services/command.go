@@ -0,0 +1,72 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/muhtutorials/reminders_cli/server/models"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CommandChannel delivers reminders by executing a local command,
+// the reminder JSON is written to its stdin and exposed through REMINDER_* environment variables
+type CommandChannel struct {
+	args    []string
+	timeout time.Duration
+}
+
+func NewCommandChannel(args []string, timeout time.Duration) CommandChannel {
+	return CommandChannel{
+		args:    args,
+		timeout: timeout,
+	}
+}
+
+// Notify runs the command for a reminder delivery, the command may print the user action
+// either as a bare action or as a JSON result, no output means the reminder was delivered
+func (c CommandChannel) Notify(delivery models.Delivery, reminder models.Reminder) (NotificationResponse, error) {
+	if len(c.args) == 0 {
+		return NotificationResponse{}, fmt.Errorf("%w: no command configured", errNotificationRejected)
+	}
+	bts, err := json.Marshal(reminder)
+	if err != nil {
+		e := models.WrapError("could not marshal json", err)
+		return NotificationResponse{}, e
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), c.timeout)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, c.args[0], c.args[1:]...)
+	cmd.Stdin = bytes.NewReader(bts)
+	cmd.Env = append(os.Environ(),
+		"REMINDER_ID="+strconv.Itoa(reminder.ID),
+		"REMINDER_TITLE="+reminder.Title,
+		"REMINDER_MESSAGE="+reminder.Message,
+		"REMINDER_DUE_AT="+reminder.DueAt.Format(time.RFC3339),
+		"REMINDER_DELIVERY_ID="+delivery.ID,
+	)
+	out, err := cmd.Output()
+	if err != nil {
+		e := models.WrapError("notification command failed", err)
+		return NotificationResponse{}, e
+	}
+
+	out = bytes.TrimSpace(out)
+	result := NotificationResult{Action: ActionDelivered}
+	switch {
+	case len(out) == 0:
+	case out[0] == '{':
+		if err := json.Unmarshal(out, &result); err != nil {
+			e := models.WrapError("could not decode notification command output", err)
+			return NotificationResponse{}, e
+		}
+	default:
+		result.Action = strings.TrimSpace(string(out))
+	}
+	return NotificationResponse{result: result}, nil
+}
@@ -0,0 +1,408 @@
+package services
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"github.com/muhtutorials/reminders_cli/server/models"
+	"log"
+	mathrand "math/rand"
+	"sort"
+	"sync"
+	"time"
+)
+
+// maxDeadDeliveries bounds the dead deliveries kept in the outbox for inspection
+const maxDeadDeliveries = 100
+
+type DeliveryRepository interface {
+	Load() ([]models.Delivery, error)
+	Save(deliveries []models.Delivery) error
+}
+
+// Backoff represents the retry policy of the failed deliveries,
+// the delay doubles from Base up to Max and a delivery is dead after Attempts failures
+type Backoff struct {
+	Attempts int
+	Base     time.Duration
+	Max      time.Duration
+}
+
+// delay computes the delay before the next attempt after the given number of failed attempts,
+// half of the delay is randomized so that deliveries failed together are not retried together
+func (b Backoff) delay(attempts int) time.Duration {
+	d := b.Base
+	for i := 1; i < attempts && d < b.Max; i++ {
+		d *= 2
+	}
+	if d > b.Max {
+		d = b.Max
+	}
+	if d <= 1 {
+		return d
+	}
+	return d/2 + time.Duration(mathrand.Int63n(int64(d/2)))
+}
+
+// Deliveries represents the persistent outbox of the firing reminders notifications,
+// failed deliveries are retried with exponential backoff until they are dead and accepted deliveries
+// wait for the channel to call back the user action, those not answered before the timeout expire
+type Deliveries struct {
+	mu         sync.Mutex
+	repo       DeliveryRepository
+	service    snapshotManager
+	timeout    time.Duration
+	backoff    Backoff
+	clock      Clock
+	deliveries map[string]*models.Delivery
+	expiries   map[string]Timer
+	wake       chan struct{}
+}
+
+func NewDeliveries(
+	repo DeliveryRepository,
+	service snapshotManager,
+	timeout time.Duration,
+	backoff Backoff,
+	clock Clock,
+) *Deliveries {
+	return &Deliveries{
+		repo:       repo,
+		service:    service,
+		timeout:    timeout,
+		backoff:    backoff,
+		clock:      clock,
+		deliveries: map[string]*models.Delivery{},
+		expiries:   map[string]Timer{},
+		wake:       make(chan struct{}, 1),
+	}
+}
+
+// Load loads the outbox and retrieves the ids of the reminders it still has deliveries pending for,
+// the deliveries are resumed by Populate once the reminders service is populated
+func (d *Deliveries) Load() (map[int]bool, error) {
+	deliveries, err := d.repo.Load()
+	if err != nil {
+		return nil, err
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	pending := map[int]bool{}
+	for i := range deliveries {
+		delivery := deliveries[i]
+		if delivery.Status != models.DeliveryDead {
+			pending[delivery.ReminderID] = true
+		}
+		d.deliveries[delivery.ID] = &delivery
+	}
+	return pending, nil
+}
+
+// Populate resumes the loaded outbox, deliveries interrupted while being sent are queued again
+func (d *Deliveries) Populate() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	now := d.clock.Now()
+	for _, delivery := range d.deliveries {
+		switch delivery.Status {
+		case models.DeliverySending:
+			delivery.Status = models.DeliveryQueued
+			delivery.NextAttemptAt = &now
+		case models.DeliveryAwaiting:
+			// the channel might still call back the user action
+			expiresIn := time.Duration(0)
+			if delivery.ExpiresAt != nil {
+				expiresIn = delivery.ExpiresAt.Sub(now)
+			}
+			d.startExpiry(delivery.ID, expiresIn)
+		}
+	}
+	d.signal()
+}
+
+// List lists the outbox deliveries with the given status (all of them if empty) in creation order
+func (d *Deliveries) List(status string) ([]models.Delivery, error) {
+	switch models.DeliveryStatus(status) {
+	case "", models.DeliveryQueued, models.DeliverySending, models.DeliveryAwaiting, models.DeliveryDead:
+	default:
+		return nil, models.DataValidationError{
+			Message: fmt.Sprintf("invalid delivery status '%s'", status),
+		}
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	deliveries := make([]models.Delivery, 0)
+	for _, delivery := range d.sorted() {
+		if status == "" || delivery.Status == models.DeliveryStatus(status) {
+			deliveries = append(deliveries, delivery)
+		}
+	}
+	return deliveries, nil
+}
+
+// Resolve applies the user action called back by the channel to the delivered reminder
+func (d *Deliveries) Resolve(deliveryID string, result NotificationResult) error {
+	d.mu.Lock()
+	delivery, ok := d.deliveries[deliveryID]
+	if !ok || (delivery.Status != models.DeliveryAwaiting && delivery.Status != models.DeliverySending) {
+		d.mu.Unlock()
+		return models.NotFoundError{
+			Message: fmt.Sprintf("could not find pending delivery with id: %s", deliveryID),
+		}
+	}
+	reminderID := delivery.ReminderID
+	d.remove(deliveryID)
+	d.mu.Unlock()
+
+	d.apply(reminderID, result)
+	return nil
+}
+
+// Wake retrieves the channel signaled whenever a delivery is queued
+func (d *Deliveries) Wake() <-chan struct{} {
+	return d.wake
+}
+
+// enqueue queues a new delivery of a firing reminder for an immediate attempt
+func (d *Deliveries) enqueue(reminder models.Reminder) {
+	now := d.clock.Now()
+	delivery := &models.Delivery{
+		ID:            newDeliveryID(),
+		ReminderID:    reminder.ID,
+		Status:        models.DeliveryQueued,
+		CreatedAt:     now,
+		NextAttemptAt: &now,
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.deliveries[delivery.ID] = delivery
+	d.save()
+	d.signal()
+}
+
+// next retrieves the time of the earliest queued delivery attempt
+func (d *Deliveries) next() (time.Time, bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	var next time.Time
+	for _, delivery := range d.deliveries {
+		if delivery.Status != models.DeliveryQueued {
+			continue
+		}
+		if next.IsZero() || delivery.NextAttemptAt.Before(next) {
+			next = *delivery.NextAttemptAt
+		}
+	}
+	return next, !next.IsZero()
+}
+
+// due marks the queued deliveries whose attempt time has come as being sent and retrieves them
+func (d *Deliveries) due(now time.Time) []models.Delivery {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	var due []models.Delivery
+	for _, delivery := range d.deliveries {
+		if delivery.Status != models.DeliveryQueued || delivery.NextAttemptAt.After(now) {
+			continue
+		}
+		sentAt := now
+		delivery.Status = models.DeliverySending
+		delivery.Attempts++
+		delivery.SentAt = &sentAt
+		delivery.NextAttemptAt = nil
+		due = append(due, *delivery)
+	}
+	if len(due) > 0 {
+		d.save()
+	}
+	return due
+}
+
+// await marks a delivery accepted by its channel as waiting for the user action until the timeout
+func (d *Deliveries) await(deliveryID string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	delivery, ok := d.deliveries[deliveryID]
+	if !ok || delivery.Status != models.DeliverySending {
+		return
+	}
+	expiresAt := d.clock.Now().Add(d.timeout)
+	delivery.Status = models.DeliveryAwaiting
+	delivery.ExpiresAt = &expiresAt
+	d.startExpiry(deliveryID, d.timeout)
+	d.save()
+}
+
+// retry queues a failed delivery for its next attempt, false is returned once the delivery is dead
+func (d *Deliveries) retry(deliveryID string, cause error) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	delivery, ok := d.deliveries[deliveryID]
+	if !ok {
+		return true
+	}
+	delivery.LastError = cause.Error()
+	if delivery.Attempts >= d.backoff.Attempts {
+		d.bury(delivery)
+		return false
+	}
+	next := d.clock.Now().Add(d.backoff.delay(delivery.Attempts))
+	delivery.Status = models.DeliveryQueued
+	delivery.NextAttemptAt = &next
+	log.Printf("delivery %s of reminder with id: %d failed %d time(s), next attempt at %v",
+		delivery.ID, delivery.ReminderID, delivery.Attempts, next.Format(time.RFC3339))
+	d.save()
+	d.signal()
+	return true
+}
+
+// postpone queues a delivery which could not be attempted again after the delay,
+// the attempt it was taken for is not counted
+func (d *Deliveries) postpone(deliveryID string, delay time.Duration) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	delivery, ok := d.deliveries[deliveryID]
+	if !ok || delivery.Status != models.DeliverySending {
+		return
+	}
+	next := d.clock.Now().Add(delay)
+	delivery.Status = models.DeliveryQueued
+	delivery.Attempts--
+	delivery.NextAttemptAt = &next
+	d.save()
+	d.signal()
+}
+
+// kill moves a delivery which can never succeed to the dead deliveries
+func (d *Deliveries) kill(deliveryID string, cause error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	delivery, ok := d.deliveries[deliveryID]
+	if !ok {
+		return
+	}
+	delivery.LastError = cause.Error()
+	d.bury(delivery)
+}
+
+// drop removes a delivery whose reminder is not firing anymore
+func (d *Deliveries) drop(deliveryID string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.remove(deliveryID)
+}
+
+// expire retries the reminder of a delivery which was not answered in time
+func (d *Deliveries) expire(deliveryID string) {
+	d.mu.Lock()
+	delivery, ok := d.deliveries[deliveryID]
+	if !ok || delivery.Status != models.DeliveryAwaiting {
+		d.mu.Unlock()
+		return
+	}
+	reminderID := delivery.ReminderID
+	d.remove(deliveryID)
+	d.mu.Unlock()
+
+	log.Printf("delivery %s of reminder with id: %d expired", deliveryID, reminderID)
+	if reminder, ok := d.service.fired(reminderID); ok {
+		d.service.retry(reminder)
+	}
+}
+
+// apply transitions the delivered reminder according to the user action on the notification
+func (d *Deliveries) apply(reminderID int, result NotificationResult) {
+	reminder, ok := d.service.fired(reminderID)
+	if !ok {
+		return
+	}
+	switch e, snooze := effect(reminder, result); e {
+	case models.EffectComplete:
+		d.service.snapshotGrooming(reminder)
+		log.Printf("reminder with: %d was completed\n", reminder.ID)
+	case models.EffectSnooze:
+		d.service.snoozeFiring(reminder, snooze)
+	case models.EffectCancel:
+		d.service.cancelFiring(reminder)
+	default:
+		d.service.retry(reminder)
+	}
+}
+
+// bury marks a delivery as dead and evicts the oldest dead deliveries, it must be called with mu held
+func (d *Deliveries) bury(delivery *models.Delivery) {
+	log.Printf("delivery %s of reminder with id: %d is dead after %d attempt(s): %s",
+		delivery.ID, delivery.ReminderID, delivery.Attempts, delivery.LastError)
+	delivery.Status = models.DeliveryDead
+	delivery.NextAttemptAt = nil
+	var dead []*models.Delivery
+	for _, other := range d.deliveries {
+		if other.Status == models.DeliveryDead {
+			dead = append(dead, other)
+		}
+	}
+	sort.Slice(dead, func(i, j int) bool {
+		return dead[i].CreatedAt.Before(dead[j].CreatedAt)
+	})
+	for i := 0; i < len(dead)-maxDeadDeliveries; i++ {
+		delete(d.deliveries, dead[i].ID)
+	}
+	d.save()
+}
+
+// remove removes a delivery from the outbox and stops its expiry, it must be called with mu held
+func (d *Deliveries) remove(deliveryID string) {
+	if expiry, ok := d.expiries[deliveryID]; ok {
+		expiry.Stop()
+		delete(d.expiries, deliveryID)
+	}
+	if _, ok := d.deliveries[deliveryID]; ok {
+		delete(d.deliveries, deliveryID)
+		d.save()
+	}
+}
+
+// startExpiry starts the expiry timer of an awaiting delivery, it must be called with mu held
+func (d *Deliveries) startExpiry(deliveryID string, in time.Duration) {
+	d.expiries[deliveryID] = d.clock.AfterFunc(in, func() { d.expire(deliveryID) })
+}
+
+// save persists the outbox, it must be called with mu held
+func (d *Deliveries) save() {
+	if err := d.repo.Save(d.sorted()); err != nil {
+		log.Printf("could not save outbox: %v", err)
+	}
+}
+
+// sorted retrieves copies of the deliveries in creation order, it must be called with mu held
+func (d *Deliveries) sorted() []models.Delivery {
+	deliveries := make([]models.Delivery, 0, len(d.deliveries))
+	for _, delivery := range d.deliveries {
+		deliveries = append(deliveries, *delivery)
+	}
+	sort.Slice(deliveries, func(i, j int) bool {
+		if deliveries[i].CreatedAt.Equal(deliveries[j].CreatedAt) {
+			return deliveries[i].ID < deliveries[j].ID
+		}
+		return deliveries[i].CreatedAt.Before(deliveries[j].CreatedAt)
+	})
+	return deliveries
+}
+
+// signal signals a queued delivery without blocking, pending signals are coalesced
+func (d *Deliveries) signal() {
+	select {
+	case d.wake <- struct{}{}:
+	default:
+	}
+}
+
+// newDeliveryID generates a random hex delivery id
+func newDeliveryID() string {
+	bts := make([]byte, 16)
+	if _, err := rand.Read(bts); err != nil {
+		// crypto/rand never fails on supported platforms
+		panic(err)
+	}
+	return hex.EncodeToString(bts)
+}
@@ -0,0 +1,177 @@
+package services
+
+import (
+	"bytes"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"github.com/muhtutorials/reminders_cli/server/models"
+	"html/template"
+	"log"
+	"mime"
+	"mime/multipart"
+	"net"
+	"net/smtp"
+	"net/textproto"
+	"strings"
+	"time"
+)
+
+var emailHTML = template.Must(template.New("email").Parse(`<!DOCTYPE html>
+<html>
+<body>
+<h2>{{.Title}}</h2>
+<p>{{.Message}}</p>
+<p><small>Due at {{.DueAt.Format "Mon, 02 Jan 2006 15:04 MST"}}</small></p>
+</body>
+</html>
+`))
+
+// SMTPConfig represents the SMTP server the email channel sends reminders through,
+// STARTTLS is required unless disabled and PLAIN auth is used when a username is provided
+type SMTPConfig struct {
+	Addr     string
+	Username string
+	Password string
+	From     string
+	To       []string
+	StartTLS bool
+	Timeout  time.Duration
+}
+
+// EmailChannel delivers reminders as emails with a plain-text and an HTML body
+type EmailChannel struct {
+	cfg   SMTPConfig
+	clock Clock
+}
+
+func NewEmailChannel(cfg SMTPConfig, clock Clock) EmailChannel {
+	return EmailChannel{
+		cfg:   cfg,
+		clock: clock,
+	}
+}
+
+// Notify emails a reminder delivery to the configured recipients, a sent email means the reminder was delivered,
+// permanent SMTP failures (5xx replies) reject the notification and any other failure retries it
+func (e EmailChannel) Notify(delivery models.Delivery, reminder models.Reminder) (NotificationResponse, error) {
+	msg, err := e.message(delivery, reminder)
+	if err != nil {
+		return NotificationResponse{}, models.WrapError("could not render email", err)
+	}
+	if err := e.send(msg); err != nil {
+		var protoErr *textproto.Error
+		if errors.As(err, &protoErr) && protoErr.Code >= 500 {
+			return NotificationResponse{}, fmt.Errorf("%w: %v", errNotificationRejected, err)
+		}
+		return NotificationResponse{}, models.WrapError("could not send email", err)
+	}
+	log.Printf("reminder with id %d was emailed to %d recipient(s)", reminder.ID, len(e.cfg.To))
+	return NotificationResponse{result: NotificationResult{Action: ActionDelivered}}, nil
+}
+
+// send sends a rendered message through the SMTP server
+func (e EmailChannel) send(msg []byte) error {
+	host, _, err := net.SplitHostPort(e.cfg.Addr)
+	if err != nil {
+		return err
+	}
+	conn, err := net.DialTimeout("tcp", e.cfg.Addr, e.cfg.Timeout)
+	if err != nil {
+		return err
+	}
+	if err := conn.SetDeadline(time.Now().Add(e.cfg.Timeout)); err != nil {
+		conn.Close()
+		return err
+	}
+	c, err := smtp.NewClient(conn, host)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer c.Close()
+
+	if e.cfg.StartTLS {
+		if ok, _ := c.Extension("STARTTLS"); !ok {
+			return errors.New("smtp server does not support STARTTLS")
+		}
+		if err := c.StartTLS(&tls.Config{ServerName: host}); err != nil {
+			return err
+		}
+	}
+	if e.cfg.Username != "" {
+		if err := c.Auth(smtp.PlainAuth("", e.cfg.Username, e.cfg.Password, host)); err != nil {
+			return err
+		}
+	}
+	if err := c.Mail(e.cfg.From); err != nil {
+		return err
+	}
+	for _, to := range e.cfg.To {
+		if err := c.Rcpt(to); err != nil {
+			return err
+		}
+	}
+	w, err := c.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(msg); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return c.Quit()
+}
+
+// message renders a reminder delivery as a multipart/alternative MIME message
+func (e EmailChannel) message(delivery models.Delivery, reminder models.Reminder) ([]byte, error) {
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+
+	text, err := mw.CreatePart(textproto.MIMEHeader{
+		"Content-Type":              {"text/plain; charset=utf-8"},
+		"Content-Transfer-Encoding": {"8bit"},
+	})
+	if err != nil {
+		return nil, err
+	}
+	fmt.Fprintf(text, "%s\r\n\r\n%s\r\n\r\nDue at %s\r\n",
+		reminder.Title, reminder.Message, reminder.DueAt.Format(time.RFC1123))
+
+	html, err := mw.CreatePart(textproto.MIMEHeader{
+		"Content-Type":              {"text/html; charset=utf-8"},
+		"Content-Transfer-Encoding": {"8bit"},
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := emailHTML.Execute(html, reminder); err != nil {
+		return nil, err
+	}
+	if err := mw.Close(); err != nil {
+		return nil, err
+	}
+
+	date := e.clock.Now()
+	if delivery.SentAt != nil {
+		date = *delivery.SentAt
+	}
+	var msg bytes.Buffer
+	headers := [][2]string{
+		{"From", e.cfg.From},
+		{"To", strings.Join(e.cfg.To, ", ")},
+		{"Subject", mime.QEncoding.Encode("utf-8", reminder.Title)},
+		{"Date", date.Format(time.RFC1123Z)},
+		{"Message-ID", fmt.Sprintf("<%s@reminders>", delivery.ID)},
+		{"MIME-Version", "1.0"},
+		{"Content-Type", "multipart/alternative; boundary=" + mw.Boundary()},
+	}
+	for _, h := range headers {
+		fmt.Fprintf(&msg, "%s: %s\r\n", h[0], h[1])
+	}
+	msg.WriteString("\r\n")
+	msg.Write(body.Bytes())
+	return msg.Bytes(), nil
+}
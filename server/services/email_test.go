@@ -0,0 +1,167 @@
+package services
+
+import (
+	"errors"
+	"github.com/muhtutorials/reminders_cli/server/models"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net"
+	"net/mail"
+	"net/textproto"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeSMTP represents an SMTP server accepting a single session,
+// it replies to RCPT with rcptReply when set and records the DATA of the session
+type fakeSMTP struct {
+	ln        net.Listener
+	rcptReply string
+	data      chan []byte
+}
+
+func newFakeSMTP(t *testing.T, rcptReply string) *fakeSMTP {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := &fakeSMTP{ln: ln, rcptReply: rcptReply, data: make(chan []byte, 1)}
+	go s.serve()
+	t.Cleanup(func() { ln.Close() })
+	return s
+}
+
+func (s *fakeSMTP) serve() {
+	conn, err := s.ln.Accept()
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+	tp := textproto.NewConn(conn)
+	_ = tp.PrintfLine("220 fake ESMTP")
+	for {
+		line, err := tp.ReadLine()
+		if err != nil {
+			return
+		}
+		switch cmd := strings.ToUpper(strings.Fields(line)[0]); cmd {
+		case "EHLO", "HELO":
+			_ = tp.PrintfLine("250 fake")
+		case "RCPT":
+			if s.rcptReply != "" {
+				_ = tp.PrintfLine(s.rcptReply)
+				continue
+			}
+			_ = tp.PrintfLine("250 ok")
+		case "DATA":
+			_ = tp.PrintfLine("354 go ahead")
+			data, err := tp.ReadDotBytes()
+			if err != nil {
+				return
+			}
+			s.data <- data
+			_ = tp.PrintfLine("250 queued")
+		case "QUIT":
+			_ = tp.PrintfLine("221 bye")
+			return
+		default:
+			_ = tp.PrintfLine("250 ok")
+		}
+	}
+}
+
+func newTestEmailChannel(s *fakeSMTP, clock Clock) EmailChannel {
+	return NewEmailChannel(SMTPConfig{
+		Addr:    s.ln.Addr().String(),
+		From:    "reminders@example.com",
+		To:      []string{"a@example.com", "b@example.com"},
+		Timeout: 5 * time.Second,
+	}, clock)
+}
+
+func TestEmailChannelSendsMessage(t *testing.T) {
+	s := newFakeSMTP(t, "")
+	// the message is dated by the clock when the delivery was never sent
+	now := time.Date(2030, 1, 2, 9, 30, 0, 0, time.UTC)
+	delivery := models.Delivery{ID: "d1", ReminderID: 3}
+	reminder := models.Reminder{ID: 3, Title: "Pay <rent>", Message: "before noon", DueAt: now}
+
+	res, err := newTestEmailChannel(s, NewFakeClock(now)).Notify(delivery, reminder)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.pending || res.result.Action != ActionDelivered {
+		t.Fatalf("got response %+v, want delivered right away", res)
+	}
+
+	msg, err := mail.ReadMessage(strings.NewReader(string(<-s.data)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for header, want := range map[string]string{
+		"From":       "reminders@example.com",
+		"To":         "a@example.com, b@example.com",
+		"Subject":    "Pay <rent>",
+		"Message-Id": "<d1@reminders>",
+		"Date":       now.Format(time.RFC1123Z),
+	} {
+		got := msg.Header.Get(header)
+		if header == "Subject" {
+			got, _ = new(mime.WordDecoder).DecodeHeader(got)
+		}
+		if got != want {
+			t.Errorf("got %s header %q, want %q", header, got, want)
+		}
+	}
+
+	mediaType, params, err := mime.ParseMediaType(msg.Header.Get("Content-Type"))
+	if err != nil || mediaType != "multipart/alternative" {
+		t.Fatalf("got content type %q, want multipart/alternative", msg.Header.Get("Content-Type"))
+	}
+	parts := map[string]string{}
+	mr := multipart.NewReader(msg.Body, params["boundary"])
+	for {
+		part, err := mr.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		body, _ := io.ReadAll(part)
+		parts[strings.Split(part.Header.Get("Content-Type"), ";")[0]] = string(body)
+	}
+	if text := parts["text/plain"]; !strings.Contains(text, "Pay <rent>") || !strings.Contains(text, "before noon") {
+		t.Errorf("got text part %q, want the title and message", text)
+	}
+	if html := parts["text/html"]; !strings.Contains(html, "<h2>Pay &lt;rent&gt;</h2>") {
+		t.Errorf("got html part %q, want the escaped title", html)
+	}
+}
+
+func TestEmailChannelErrors(t *testing.T) {
+	reminder := models.Reminder{ID: 3, Title: "title", Message: "message"}
+
+	// permanent failures reject the notification
+	_, err := newTestEmailChannel(newFakeSMTP(t, "550 no such user"), NewRealClock()).Notify(models.Delivery{ID: "d1"}, reminder)
+	if !errors.Is(err, errNotificationRejected) {
+		t.Fatalf("got error %v, want a rejected notification", err)
+	}
+
+	// transient failures are retried
+	_, err = newTestEmailChannel(newFakeSMTP(t, "451 try again later"), NewRealClock()).Notify(models.Delivery{ID: "d2"}, reminder)
+	if err == nil || errors.Is(err, errNotificationRejected) {
+		t.Fatalf("got error %v, want a retryable error", err)
+	}
+
+	// STARTTLS is required when enabled
+	s := newFakeSMTP(t, "")
+	channel := newTestEmailChannel(s, NewRealClock())
+	channel.cfg.StartTLS = true
+	if _, err := channel.Notify(models.Delivery{ID: "d3"}, reminder); err == nil || !strings.Contains(err.Error(), "STARTTLS") {
+		t.Fatalf("got error %v, want STARTTLS to be required", err)
+	}
+}
@@ -3,6 +3,8 @@ package services
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"github.com/muhtutorials/reminders_cli/server/models"
 	"io"
 	"net/http"
@@ -11,53 +13,102 @@ import (
 
 type HTTPClient struct {
 	notifierURL string
+	callbackURL string
 	client      *http.Client
 }
 
-func NewHTTPClient(url string) HTTPClient {
+// NewHTTPClient creates the notifier service client,
+// callbackURL is the backend API URL the notifier service sends the user actions to
+func NewHTTPClient(url, callbackURL string) HTTPClient {
 	return HTTPClient{
 		notifierURL: url,
+		callbackURL: callbackURL,
 		client: &http.Client{
 			Timeout: 20 * time.Second,
 		},
 	}
 }
 
-// NotificationResponse represents OS notification response for background notifier
+// errNotificationRejected is returned when the notifier service refuses a notification,
+// retrying such notification would never succeed
+var errNotificationRejected = errors.New("notification was rejected by the notifier service")
+
+// NotificationResponse represents OS notification response for background notifier,
+// a pending response means the action is called back later for the delivery
 type NotificationResponse struct {
-	completed   bool
-	retryPeriod time.Duration
+	result  NotificationResult
+	pending bool
+}
+
+// Notify pushes a given reminder delivery to the notifier service,
+// the notifier acknowledges it right away and calls back the user action later,
+// notifiers replying with the action right away are supported as well
+func (h HTTPClient) Notify(delivery models.Delivery, reminder models.Reminder) (NotificationResponse, error) {
+	return postNotification(h.client, h.notifierURL+"/notify", h.callbackURL, delivery, reminder, "")
 }
 
-// Notify pushes a given reminder to the notifier service
-// if the reminder is nil, means the record must be retried
-func (h HTTPClient) Notify(reminder models.Reminder) (NotificationResponse, error) {
-	var notifierResponse struct {
-		Action string `json:"action"`
+// Health probes the health endpoint of the notifier service
+func (h HTTPClient) Health() error {
+	res, err := h.client.Get(h.notifierURL + "/health")
+	if err != nil {
+		return models.WrapError("notifier service is unavailable", err)
 	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return fmt.Errorf("notifier service health responded with status code %d", res.StatusCode)
+	}
+	return nil
+}
 
-	bts, err := json.Marshal(reminder)
+// postNotification posts a reminder delivery as JSON to the given URL along with the hints of its priority
+// and decodes the user action, emptyAction is reported when the receiver responds without a body
+func postNotification(
+	client *http.Client,
+	url, callbackURL string,
+	delivery models.Delivery,
+	reminder models.Reminder,
+	emptyAction string,
+) (NotificationResponse, error) {
+	notification := struct {
+		models.Reminder
+		notificationHints
+		DeliveryID  string `json:"delivery_id"`
+		CallbackURL string `json:"callback_url"`
+	}{
+		Reminder:          reminder,
+		notificationHints: priorityHints(reminder),
+		DeliveryID:        delivery.ID,
+		CallbackURL:       callbackURL + "/notifications/" + delivery.ID + "/result",
+	}
+	bts, err := json.Marshal(notification)
 	if err != nil {
 		e := models.WrapError("could not marshal json", err)
 		return NotificationResponse{}, e
 	}
 
-	res, err := h.client.Post(h.notifierURL+"/notify", "application/json", bytes.NewReader(bts))
+	res, err := client.Post(url, "application/json", bytes.NewReader(bts))
 	if err != nil {
-		e := models.WrapError("notifier service is unavailable", err)
+		e := models.WrapError("notification receiver is unavailable", err)
 		return NotificationResponse{}, e
 	}
+	defer res.Body.Close()
 
-	err = json.NewDecoder(res.Body).Decode(&notifierResponse)
-	if err != nil && err != io.EOF {
-		e := models.WrapError("could not decode notifier response", err)
-		return NotificationResponse{}, e
+	if res.StatusCode >= 400 && res.StatusCode < 500 {
+		return NotificationResponse{}, fmt.Errorf("%w: status code %d", errNotificationRejected, res.StatusCode)
+	}
+	if res.StatusCode >= 500 {
+		return NotificationResponse{}, fmt.Errorf("notification receiver responded with status code %d", res.StatusCode)
+	}
+	if res.StatusCode == http.StatusAccepted {
+		return NotificationResponse{pending: true}, nil
 	}
 
-	action := notifierResponse.Action
-	if action == "dismissed" {
-		return NotificationResponse{completed: true}, nil
+	result := NotificationResult{Action: emptyAction}
+	err = json.NewDecoder(res.Body).Decode(&result)
+	if err != nil && err != io.EOF {
+		e := models.WrapError("could not decode notification response", err)
+		return NotificationResponse{}, e
 	}
 
-	return NotificationResponse{}, nil
+	return NotificationResponse{result: result}, nil
 }
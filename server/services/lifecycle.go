@@ -0,0 +1,213 @@
+package services
+
+import (
+	"fmt"
+	"github.com/muhtutorials/reminders_cli/server/models"
+	"log"
+	"time"
+)
+
+// transitions represents the allowed reminder status transitions,
+// every status can go back to pending when the reminder is rescheduled or reopened
+var transitions = map[models.Status][]models.Status{
+	models.StatusPending:   {models.StatusFiring, models.StatusSnoozed, models.StatusCompleted, models.StatusCancelled},
+	models.StatusFiring:    {models.StatusFiring, models.StatusSnoozed, models.StatusCompleted, models.StatusCancelled, models.StatusFailed},
+	models.StatusSnoozed:   {models.StatusFiring, models.StatusSnoozed, models.StatusCompleted, models.StatusCancelled},
+	models.StatusCompleted: {},
+	models.StatusCancelled: {},
+	models.StatusFailed:    {},
+}
+
+// Cancel cancels an active reminder so it is never notified again
+func (rs *Reminders) Cancel(id int) (models.Reminder, error) {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+	index, reminder, err := rs.find(id)
+	if err != nil {
+		return models.Reminder{}, err
+	}
+	now := rs.clock.Now()
+	if err := transition(&reminder, models.StatusCancelled, now); err != nil {
+		return models.Reminder{}, err
+	}
+	reminder.ModifiedAt = now
+	rs.store(index, reminder)
+	rs.publish(EventReminderCancelled, reminder)
+	return reminder, nil
+}
+
+// Complete acknowledges an active reminder,
+// recurring reminders are advanced to their next occurrence
+func (rs *Reminders) Complete(id int) (models.Reminder, error) {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+	index, reminder, err := rs.find(id)
+	if err != nil {
+		return models.Reminder{}, err
+	}
+	if !canTransition(reminder.Status, models.StatusCompleted) {
+		return models.Reminder{}, transitionError(reminder, models.StatusCompleted)
+	}
+	now := rs.clock.Now()
+	reminder.ModifiedAt = now
+	return rs.complete(index, reminder, now), nil
+}
+
+// SnoozeBody represents the model for snoozing a reminder,
+// the reminder is notified again either after Duration or at Until
+type SnoozeBody struct {
+	ID       int
+	Duration time.Duration
+	Until    time.Time
+}
+
+// Snooze postpones the next notification of an active reminder
+func (rs *Reminders) Snooze(body SnoozeBody) (models.Reminder, error) {
+	now := rs.clock.Now()
+	until, _, err := resolveDueAt(body.Duration, body.Until, "until", now)
+	if err != nil {
+		return models.Reminder{}, err
+	}
+	if until.IsZero() {
+		err := models.DataValidationError{
+			Message: "either duration or until must be provided",
+		}
+		return models.Reminder{}, err
+	}
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+	index, reminder, err := rs.find(body.ID)
+	if err != nil {
+		return models.Reminder{}, err
+	}
+	if err := transition(&reminder, models.StatusSnoozed, now); err != nil {
+		return models.Reminder{}, err
+	}
+	// the recurrence keeps its current occurrence, only this notification is postponed
+	reminder.DueAt = until.In(reminderLocation(reminder))
+	reminder.ModifiedAt = now
+	rs.store(index, reminder)
+	rs.scheduler.Schedule(reminder.ID, reminder.DueAt)
+	rs.publish(EventReminderSnoozed, reminder)
+	return reminder, nil
+}
+
+// ReopenBody represents the model for reopening a reminder,
+// the reminder keeps its due time unless a new Duration or DueAt is provided
+type ReopenBody struct {
+	ID       int
+	Duration time.Duration
+	DueAt    time.Time
+}
+
+// Reopen moves a completed, cancelled or failed reminder back to pending
+func (rs *Reminders) Reopen(body ReopenBody) (models.Reminder, error) {
+	now := rs.clock.Now()
+	dueAt, duration, err := resolveDueAt(body.Duration, body.DueAt, "due_at", now)
+	if err != nil {
+		return models.Reminder{}, err
+	}
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+	index, reminder, err := rs.find(body.ID)
+	if err != nil {
+		return models.Reminder{}, err
+	}
+	if reminder.Status.Active() {
+		return models.Reminder{}, transitionError(reminder, models.StatusPending)
+	}
+	if !dueAt.IsZero() {
+		reminder.Duration = duration
+		reminder.DueAt = dueAt
+		if reminder.Recurrence != nil {
+			recurrence := *reminder.Recurrence
+			recurrence.Current = dueAt
+			reminder.Recurrence = &recurrence
+		}
+		reminder = localize(reminder)
+	}
+	if !reminder.DueAt.After(now) {
+		err := models.DataValidationError{
+			Message: "the reminder due time has passed, either duration or due_at must be provided",
+		}
+		return models.Reminder{}, err
+	}
+	if err := transition(&reminder, models.StatusPending, now); err != nil {
+		return models.Reminder{}, err
+	}
+	reminder.ModifiedAt = now
+	rs.store(index, reminder)
+	rs.scheduler.Schedule(reminder.ID, reminder.DueAt)
+	rs.publish(EventReminderReopened, reminder)
+	return reminder, nil
+}
+
+// complete completes the current occurrence of a reminder,
+// recurring reminders are advanced to their next occurrence instead
+func (rs *Reminders) complete(index int, reminder models.Reminder, now time.Time) models.Reminder {
+	after := now
+	if reminder.Missed && rs.catchUpPolicy == CatchUpFire && reminder.Recurrence != nil {
+		// every occurrence missed while the server was down is fired in turn
+		after = reminder.Recurrence.Current
+	}
+	if next, ok := nextOccurrence(reminder, after); ok {
+		log.Printf("reminder with id: %d repeats at %v", next.ID, next.DueAt)
+		_ = transition(&next, models.StatusPending, now)
+		next.Missed = !next.DueAt.After(now)
+		rs.store(index, next)
+		rs.scheduler.Schedule(next.ID, next.DueAt)
+		rs.publish(EventReminderCompleted, next)
+		return next
+	}
+	_ = transition(&reminder, models.StatusCompleted, now)
+	rs.store(index, reminder)
+	rs.publish(EventReminderCompleted, reminder)
+	return reminder
+}
+
+// transition moves a reminder to the given status and updates its lifecycle timestamps
+func transition(reminder *models.Reminder, to models.Status, now time.Time) error {
+	if !canTransition(reminder.Status, to) {
+		return transitionError(*reminder, to)
+	}
+	switch to {
+	case models.StatusPending:
+		reminder.FiredAt = nil
+		reminder.CompletedAt = nil
+		reminder.Missed = false
+	case models.StatusFiring:
+		if reminder.Status != models.StatusFiring {
+			reminder.FiredAt = &now
+		}
+	case models.StatusCompleted:
+		reminder.CompletedAt = &now
+	}
+	reminder.Status = to
+	return nil
+}
+
+func canTransition(from, to models.Status) bool {
+	if to == models.StatusPending {
+		return true
+	}
+	for _, s := range transitions[from] {
+		if s == to {
+			return true
+		}
+	}
+	return false
+}
+
+func transitionError(reminder models.Reminder, to models.Status) error {
+	return models.ConflictError{
+		Message: fmt.Sprintf("reminder with id: %d cannot go from %s to %s", reminder.ID, reminder.Status, to),
+	}
+}
+
+// legacyStatus derives the status of reminders saved before the lifecycle was introduced
+func legacyStatus(reminder models.Reminder, now time.Time) models.Status {
+	if reminder.DueAt.After(now) {
+		return models.StatusPending
+	}
+	return models.StatusCompleted
+}
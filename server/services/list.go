@@ -0,0 +1,263 @@
+package services
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"github.com/muhtutorials/reminders_cli/server/models"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	// StatusFilterUncompleted matches the pending, firing & snoozed reminders
+	StatusFilterUncompleted = "uncompleted"
+	// StatusFilterOverdue matches the firing reminders and the active ones whose due time has passed
+	StatusFilterOverdue = "overdue"
+
+	SortByID         = "id"
+	SortByTitle      = "title"
+	SortByCreatedAt  = "created_at"
+	SortByModifiedAt = "modified_at"
+	SortByDueAt      = "due_at"
+	SortByPriority   = "priority"
+
+	OrderAsc  = "asc"
+	OrderDesc = "desc"
+
+	defaultListLimit = 50
+	maxListLimit     = 500
+)
+
+// TimeRange represents an optional [After, Before] time interval, zero values are ignored
+type TimeRange struct {
+	After  time.Time
+	Before time.Time
+}
+
+// contains checks whether t falls inside the time range
+func (tr TimeRange) contains(t time.Time) bool {
+	if !tr.After.IsZero() && t.Before(tr.After) {
+		return false
+	}
+	if !tr.Before.IsZero() && t.After(tr.Before) {
+		return false
+	}
+	return true
+}
+
+// ReminderQuery represents the model for listing reminders,
+// a non-nil Missed only matches the reminders whose missed flag equals it
+// and Priorities match the reminders having any of them
+type ReminderQuery struct {
+	Status     string
+	Missed     *bool
+	Tags       TagFilter
+	Priorities []string
+	Created    TimeRange
+	Modified   TimeRange
+	Due        TimeRange
+	Title      string
+	Sort       string
+	Order      string
+	Limit      int
+	Next       string
+}
+
+// ReminderPage represents a single page of listed reminders
+type ReminderPage struct {
+	Reminders []models.Reminder `json:"reminders"`
+	Next      string            `json:"next,omitempty"`
+}
+
+// listCursor represents the position of the last listed reminder
+type listCursor struct {
+	Key string `json:"k"`
+	ID  int    `json:"id"`
+}
+
+// List lists the reminders matching the given query
+func (rs *Reminders) List(query ReminderQuery) (ReminderPage, error) {
+	if err := query.validate(); err != nil {
+		return ReminderPage{}, err
+	}
+	var cursor *listCursor
+	if query.Next != "" {
+		c, err := decodeCursor(query.Next)
+		if err != nil {
+			return ReminderPage{}, err
+		}
+		cursor = &c
+	}
+
+	now := rs.clock.Now()
+	var reminders []models.Reminder
+	rs.mu.RLock()
+	for id := range rs.state.All {
+		_, reminder := rs.state.All.flatten(id)
+		if query.matches(reminder, now) {
+			reminders = append(reminders, reminder)
+		}
+	}
+	rs.mu.RUnlock()
+
+	desc := query.Order == OrderDesc
+	sort.Slice(reminders, func(i, j int) bool {
+		a := listCursor{Key: sortKey(reminders[i], query.Sort), ID: reminders[i].ID}
+		b := listCursor{Key: sortKey(reminders[j], query.Sort), ID: reminders[j].ID}
+		if desc {
+			return a.after(b)
+		}
+		return b.after(a)
+	})
+
+	page := ReminderPage{Reminders: make([]models.Reminder, 0, query.Limit)}
+	for _, reminder := range reminders {
+		current := listCursor{Key: sortKey(reminder, query.Sort), ID: reminder.ID}
+		if cursor != nil && (desc && !cursor.after(current) || !desc && !current.after(*cursor)) {
+			continue
+		}
+		if len(page.Reminders) == query.Limit {
+			last := page.Reminders[len(page.Reminders)-1]
+			page.Next = encodeCursor(listCursor{Key: sortKey(last, query.Sort), ID: last.ID})
+			break
+		}
+		page.Reminders = append(page.Reminders, reminder)
+	}
+	return page, nil
+}
+
+// validate validates the query and fills in the defaults
+func (q *ReminderQuery) validate() error {
+	switch models.Status(q.Status) {
+	case "", StatusFilterUncompleted, StatusFilterOverdue,
+		models.StatusPending, models.StatusFiring, models.StatusSnoozed,
+		models.StatusCompleted, models.StatusCancelled, models.StatusFailed:
+	default:
+		return models.DataValidationError{
+			Message: fmt.Sprintf("invalid status '%s'", q.Status),
+		}
+	}
+	switch q.Sort {
+	case "":
+		q.Sort = SortByID
+	case SortByID, SortByTitle, SortByCreatedAt, SortByModifiedAt, SortByDueAt, SortByPriority:
+	default:
+		return models.DataValidationError{
+			Message: fmt.Sprintf("invalid sort field '%s'", q.Sort),
+		}
+	}
+	switch q.Order {
+	case "":
+		q.Order = OrderAsc
+	case OrderAsc, OrderDesc:
+	default:
+		return models.DataValidationError{
+			Message: fmt.Sprintf("invalid sort order '%s'", q.Order),
+		}
+	}
+	if q.Limit < 0 || q.Limit > maxListLimit {
+		return models.DataValidationError{
+			Message: fmt.Sprintf("limit must be between 1 and %d", maxListLimit),
+		}
+	}
+	if q.Limit == 0 {
+		q.Limit = defaultListLimit
+	}
+	for i, p := range q.Priorities {
+		priority, err := ParsePriority(p)
+		if err != nil {
+			return err
+		}
+		q.Priorities[i] = string(priority)
+	}
+	return q.Tags.validate()
+}
+
+// matches checks whether a reminder satisfies all the query filters
+func (q ReminderQuery) matches(r models.Reminder, now time.Time) bool {
+	switch q.Status {
+	case "":
+	case StatusFilterUncompleted:
+		if !r.Status.Active() {
+			return false
+		}
+	case StatusFilterOverdue:
+		if r.Status != models.StatusFiring && !(r.Status.Active() && r.DueAt.Before(now)) {
+			return false
+		}
+	default:
+		if r.Status != models.Status(q.Status) {
+			return false
+		}
+	}
+	if !q.Created.contains(r.CreatedAt) || !q.Modified.contains(r.ModifiedAt) || !q.Due.contains(r.DueAt) {
+		return false
+	}
+	if q.Title != "" && !strings.Contains(strings.ToLower(r.Title), strings.ToLower(q.Title)) {
+		return false
+	}
+	if q.Missed != nil && r.Missed != *q.Missed {
+		return false
+	}
+	if len(q.Priorities) > 0 && !q.hasPriority(reminderPriority(r)) {
+		return false
+	}
+	return q.Tags.matches(r.Tags)
+}
+
+// hasPriority checks whether the query filters on the given priority
+func (q ReminderQuery) hasPriority(priority models.Priority) bool {
+	for _, p := range q.Priorities {
+		if models.Priority(p) == priority {
+			return true
+		}
+	}
+	return false
+}
+
+// after checks whether the cursor position comes after the other one
+func (c listCursor) after(other listCursor) bool {
+	if c.Key != other.Key {
+		return c.Key > other.Key
+	}
+	return c.ID > other.ID
+}
+
+// sortKey builds a lexicographically comparable key of the reminder sort field
+func sortKey(r models.Reminder, field string) string {
+	const timeLayout = "2006-01-02T15:04:05.000000000"
+	switch field {
+	case SortByTitle:
+		return strings.ToLower(r.Title)
+	case SortByCreatedAt:
+		return r.CreatedAt.UTC().Format(timeLayout)
+	case SortByModifiedAt:
+		return r.ModifiedAt.UTC().Format(timeLayout)
+	case SortByDueAt:
+		return r.DueAt.UTC().Format(timeLayout)
+	case SortByPriority:
+		return priorityKey(r)
+	default:
+		return ""
+	}
+}
+
+func encodeCursor(c listCursor) string {
+	bts, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(bts)
+}
+
+func decodeCursor(token string) (listCursor, error) {
+	var c listCursor
+	invalid := models.DataValidationError{Message: "invalid next token"}
+	bts, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return c, invalid
+	}
+	if err := json.Unmarshal(bts, &c); err != nil {
+		return c, invalid
+	}
+	return c, nil
+}
@@ -0,0 +1,124 @@
+package services
+
+import (
+	"errors"
+	"github.com/muhtutorials/reminders_cli/server/models"
+	"log"
+	"sync"
+	"time"
+)
+
+var (
+	// errPoolFull is returned when the queue of the worker pool is full
+	errPoolFull = errors.New("notification queue is full")
+	// errInFlight is returned when a delivery of the same reminder is already queued or being sent
+	errInFlight = errors.New("reminder is already being notified")
+)
+
+// PoolStats represents the state and the counters of the notification worker pool,
+// Rejected counts the deliveries postponed because the queue was full
+type PoolStats struct {
+	Workers    int    `json:"workers"`
+	QueueDepth int    `json:"queue_depth"`
+	Queued     int    `json:"queued"`
+	InFlight   int    `json:"in_flight"`
+	Submitted  uint64 `json:"submitted"`
+	Completed  uint64 `json:"completed"`
+	Rejected   uint64 `json:"rejected"`
+	Duplicates uint64 `json:"duplicates"`
+}
+
+// WorkerPool represents a bounded pool of workers sending the notification deliveries,
+// a reminder is delivered by at most one worker at a time
+type WorkerPool struct {
+	mu       sync.Mutex
+	jobs     chan models.Delivery
+	inFlight map[int]bool
+	stats    PoolStats
+	clock    Clock
+	wg       sync.WaitGroup
+}
+
+func NewWorkerPool(workers, depth int, clock Clock) *WorkerPool {
+	return &WorkerPool{
+		jobs:     make(chan models.Delivery, depth),
+		inFlight: map[int]bool{},
+		clock:    clock,
+		stats: PoolStats{
+			Workers:    workers,
+			QueueDepth: depth,
+		},
+	}
+}
+
+// Start starts the workers handling the submitted deliveries
+func (p *WorkerPool) Start(handler func(delivery models.Delivery)) {
+	for i := 0; i < p.stats.Workers; i++ {
+		p.wg.Add(1)
+		go func() {
+			defer p.wg.Done()
+			for delivery := range p.jobs {
+				handler(delivery)
+				p.done(delivery)
+			}
+		}()
+	}
+}
+
+// Submit queues a delivery without blocking, it fails when the queue is full
+// or when a delivery of the same reminder is already queued or being sent
+func (p *WorkerPool) Submit(delivery models.Delivery) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.inFlight[delivery.ReminderID] {
+		p.stats.Duplicates++
+		return errInFlight
+	}
+	select {
+	case p.jobs <- delivery:
+	default:
+		p.stats.Rejected++
+		return errPoolFull
+	}
+	p.inFlight[delivery.ReminderID] = true
+	p.stats.Submitted++
+	return nil
+}
+
+// Stats retrieves the current pool stats
+func (p *WorkerPool) Stats() PoolStats {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	stats := p.stats
+	stats.Queued = len(p.jobs)
+	stats.InFlight = len(p.inFlight)
+	return stats
+}
+
+// Stop stops accepting deliveries and waits for the workers to finish up to the given timeout
+func (p *WorkerPool) Stop(timeout time.Duration) {
+	p.mu.Lock()
+	close(p.jobs)
+	p.mu.Unlock()
+
+	stopped := make(chan struct{})
+	go func() {
+		p.wg.Wait()
+		close(stopped)
+	}()
+	timer := p.clock.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case <-stopped:
+	case <-timer.C():
+		log.Printf("notification workers did not finish within %v", timeout)
+	}
+}
+
+// done releases the reminder of a handled delivery
+func (p *WorkerPool) done(delivery models.Delivery) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	delete(p.inFlight, delivery.ReminderID)
+	p.stats.Completed++
+}
@@ -0,0 +1,73 @@
+package services
+
+import (
+	"fmt"
+	"github.com/muhtutorials/reminders_cli/server/models"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// priorityRanks orders the priorities from the least to the most pressing
+var priorityRanks = map[models.Priority]int{
+	models.PriorityLow:    0,
+	models.PriorityNormal: 1,
+	models.PriorityHigh:   2,
+	models.PriorityUrgent: 3,
+}
+
+// ParsePriority parses a priority name
+func ParsePriority(s string) (models.Priority, error) {
+	priority := models.Priority(strings.ToLower(s))
+	if _, ok := priorityRanks[priority]; !ok {
+		return "", models.DataValidationError{
+			Message: fmt.Sprintf("invalid priority '%s', expected low, normal, high or urgent", s),
+		}
+	}
+	return priority, nil
+}
+
+// reminderPriority retrieves the priority of a reminder, reminders created before priorities existed are normal
+func reminderPriority(reminder models.Reminder) models.Priority {
+	if reminder.Priority == "" {
+		return models.PriorityNormal
+	}
+	return reminder.Priority
+}
+
+// retryPeriod retrieves how long a firing reminder waits before it is notified again,
+// urgent reminders retry 4 times and high ones 2 times as often while low ones retry half as often
+func retryPeriod(reminder models.Reminder) time.Duration {
+	switch reminderPriority(reminder) {
+	case models.PriorityUrgent:
+		return reminder.RetryPeriod / 4
+	case models.PriorityHigh:
+		return reminder.RetryPeriod / 2
+	case models.PriorityLow:
+		return reminder.RetryPeriod * 2
+	}
+	return reminder.RetryPeriod
+}
+
+// priorityKey builds a sort key ordering the reminders from the least to the most pressing
+func priorityKey(reminder models.Reminder) string {
+	return strconv.Itoa(priorityRanks[reminderPriority(reminder)])
+}
+
+// notificationHints represents how a notifier should present the notification of a reminder,
+// Urgency follows the desktop notification urgency levels (low, normal or critical)
+type notificationHints struct {
+	Urgency string `json:"urgency"`
+	Sound   bool   `json:"sound"`
+}
+
+// priorityHints retrieves the notification hints of a reminder
+func priorityHints(reminder models.Reminder) notificationHints {
+	switch reminderPriority(reminder) {
+	case models.PriorityLow:
+		return notificationHints{Urgency: "low"}
+	case models.PriorityUrgent:
+		return notificationHints{Urgency: "critical", Sound: true}
+	}
+	return notificationHints{Urgency: "normal", Sound: true}
+}
@@ -0,0 +1,339 @@
+package services
+
+import (
+	"fmt"
+	"github.com/muhtutorials/reminders_cli/server/models"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// maxQuietSteps bounds the chaining of adjacent quiet windows, so windows covering the whole week still end
+const maxQuietSteps = 64
+
+// dayNames maps the quiet-hours day names to weekdays
+var dayNames = map[string]time.Weekday{
+	"sun": time.Sunday,
+	"mon": time.Monday,
+	"tue": time.Tuesday,
+	"wed": time.Wednesday,
+	"thu": time.Thursday,
+	"fri": time.Friday,
+	"sat": time.Saturday,
+}
+
+// QuietWindow represents a weekly quiet-hours window from Start to End (minutes since midnight)
+// on the given Days in Location, a window ending before it starts spans midnight
+type QuietWindow struct {
+	Days     [7]bool
+	Start    int
+	End      int
+	Location *time.Location
+}
+
+// ParseQuietWindow parses a window formatted as "[days] HH:MM-HH:MM [time zone]",
+// days are comma separated names or ranges (e.g. mon-fri,sun), every day is used when they are omitted
+// and the server time zone when the time zone is omitted
+func ParseQuietWindow(spec string) (QuietWindow, error) {
+	return parseQuietWindow(spec, time.Local)
+}
+
+// parseQuietWindow parses a window, the given time zone is used when the window has none
+func parseQuietWindow(spec string, loc *time.Location) (QuietWindow, error) {
+	w := QuietWindow{Location: loc}
+	fields := strings.Fields(spec)
+	i := 0
+	for i < len(fields) && !strings.Contains(fields[i], ":") {
+		i++
+	}
+	if i > 1 || i == len(fields) || len(fields) > i+2 {
+		return w, invalidQuietWindow(spec, "expected [days] HH:MM-HH:MM [time zone]")
+	}
+	if i == 1 {
+		if err := w.parseDays(fields[0]); err != nil {
+			return w, invalidQuietWindow(spec, err.Error())
+		}
+	} else {
+		w.Days = [7]bool{true, true, true, true, true, true, true}
+	}
+	start, end, ok := strings.Cut(fields[i], "-")
+	if !ok {
+		return w, invalidQuietWindow(spec, "time range must be formatted as HH:MM-HH:MM")
+	}
+	var err error
+	if w.Start, err = parseClock(start, false); err != nil {
+		return w, invalidQuietWindow(spec, err.Error())
+	}
+	if w.End, err = parseClock(end, true); err != nil {
+		return w, invalidQuietWindow(spec, err.Error())
+	}
+	if w.Start == w.End {
+		return w, invalidQuietWindow(spec, "window cannot start and end at the same time")
+	}
+	if len(fields) == i+2 {
+		if w.Location, err = time.LoadLocation(fields[i+1]); err != nil {
+			return w, invalidQuietWindow(spec, fmt.Sprintf("unknown time zone '%s'", fields[i+1]))
+		}
+	}
+	return w, nil
+}
+
+// String formats the window back to its normalized spec
+func (w QuietWindow) String() string {
+	var parts []string
+	var days []string
+	for d := time.Sunday; d <= time.Saturday; d++ {
+		// weeks start on monday
+		day := (d + 1) % 7
+		if w.Days[day] {
+			days = append(days, strings.ToLower(day.String()[:3]))
+		}
+	}
+	if len(days) < 7 {
+		parts = append(parts, strings.Join(days, ","))
+	}
+	parts = append(parts, fmt.Sprintf("%02d:%02d-%02d:%02d", w.Start/60, w.Start%60, w.End/60, w.End%60))
+	if w.Location != time.Local {
+		parts = append(parts, w.Location.String())
+	}
+	return strings.Join(parts, " ")
+}
+
+// end retrieves the end of the window occurrence t falls into
+func (w QuietWindow) end(t time.Time) (time.Time, bool) {
+	local := t.In(w.Location)
+	y, m, d := local.Date()
+	// the window of the previous day may span midnight
+	for offset := -1; offset <= 0; offset++ {
+		day := time.Date(y, m, d+offset, 0, 0, 0, 0, w.Location)
+		if !w.Days[day.Weekday()] {
+			continue
+		}
+		start := time.Date(y, m, d+offset, 0, w.Start, 0, 0, w.Location)
+		end := time.Date(y, m, d+offset, 0, w.End, 0, 0, w.Location)
+		if w.End <= w.Start {
+			end = time.Date(y, m, d+offset+1, 0, w.End, 0, 0, w.Location)
+		}
+		if !t.Before(start) && t.Before(end) {
+			return end, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// parseDays parses comma separated day names and ranges
+func (w *QuietWindow) parseDays(s string) error {
+	for _, part := range strings.Split(strings.ToLower(s), ",") {
+		from, to, isRange := strings.Cut(part, "-")
+		first, ok := dayNames[from]
+		if !ok {
+			return fmt.Errorf("unknown day '%s'", from)
+		}
+		last := first
+		if isRange {
+			if last, ok = dayNames[to]; !ok {
+				return fmt.Errorf("unknown day '%s'", to)
+			}
+		}
+		for day := first; ; day = (day + 1) % 7 {
+			w.Days[day] = true
+			if day == last {
+				break
+			}
+		}
+	}
+	return nil
+}
+
+// parseClock parses an HH:MM time of day to minutes since midnight, 24:00 is only allowed as an end
+func parseClock(s string, end bool) (int, error) {
+	invalid := fmt.Errorf("invalid time '%s', expected HH:MM", s)
+	if len(s) != 5 || s[2] != ':' {
+		return 0, invalid
+	}
+	h, err := strconv.Atoi(s[:2])
+	if err != nil {
+		return 0, invalid
+	}
+	m, err := strconv.Atoi(s[3:])
+	if err != nil {
+		return 0, invalid
+	}
+	if end && h == 24 && m == 0 {
+		return 24 * 60, nil
+	}
+	if h < 0 || h > 23 || m < 0 || m > 59 {
+		return 0, invalid
+	}
+	return h*60 + m, nil
+}
+
+func invalidQuietWindow(spec, reason string) error {
+	return models.DataValidationError{
+		Message: fmt.Sprintf("invalid quiet hours '%s': %s", spec, reason),
+	}
+}
+
+// normalizeQuietHours validates the quiet-hours specs of a reminder and formats them back to their normalized spec
+func normalizeQuietHours(specs []string) ([]string, error) {
+	normalized := make([]string, len(specs))
+	for i, spec := range specs {
+		w, err := ParseQuietWindow(spec)
+		if err != nil {
+			return nil, err
+		}
+		normalized[i] = w.String()
+	}
+	return normalized, nil
+}
+
+// DNDBody represents the model for toggling do-not-disturb,
+// an enabled do-not-disturb lasts for Duration, until Until or until it is disabled
+type DNDBody struct {
+	Enabled  bool
+	Duration time.Duration
+	Until    time.Time
+}
+
+// DNDState represents the do-not-disturb state along with the server quiet hours,
+// QuietUntil is set while the server quiet hours or do-not-disturb hold the notifications
+type DNDState struct {
+	Enabled    bool       `json:"enabled"`
+	Until      *time.Time `json:"until,omitempty"`
+	QuietHours []string   `json:"quiet_hours,omitempty"`
+	QuietUntil *time.Time `json:"quiet_until,omitempty"`
+}
+
+// QuietHours represents the server quiet-hours windows and the do-not-disturb toggle,
+// the reminders due while either of them is in effect are held until it ends or dropped if they are low priority
+// and dropLow is set, urgent reminders bypass the quiet-hours windows but not do-not-disturb
+type QuietHours struct {
+	mu       sync.Mutex
+	windows  []QuietWindow
+	dropLow  bool
+	clock    Clock
+	dnd      bool
+	dndUntil time.Time
+	wake     chan struct{}
+}
+
+func NewQuietHours(windows []QuietWindow, dropLow bool, clock Clock) *QuietHours {
+	return &QuietHours{
+		windows: windows,
+		dropLow: dropLow,
+		clock:   clock,
+		wake:    make(chan struct{}, 1),
+	}
+}
+
+// SetDND enables or disables do-not-disturb, disabling it releases the held reminders
+func (q *QuietHours) SetDND(body DNDBody) (DNDState, error) {
+	now := q.clock.Now()
+	var until time.Time
+	if body.Enabled {
+		var err error
+		if until, _, err = resolveDueAt(body.Duration, body.Until, "until", now); err != nil {
+			return DNDState{}, err
+		}
+	}
+	q.mu.Lock()
+	q.dnd, q.dndUntil = body.Enabled, until
+	q.mu.Unlock()
+	q.signal()
+	return q.DND(), nil
+}
+
+// DND retrieves the do-not-disturb state
+func (q *QuietHours) DND() DNDState {
+	now := q.clock.Now()
+	q.mu.Lock()
+	state := DNDState{Enabled: q.active(now)}
+	if state.Enabled && !q.dndUntil.IsZero() {
+		until := q.dndUntil
+		state.Until = &until
+	}
+	for _, w := range q.windows {
+		state.QuietHours = append(state.QuietHours, w.String())
+	}
+	q.mu.Unlock()
+	if until, quiet := q.until(q.serverWindows(), now); quiet && !until.IsZero() {
+		state.QuietUntil = &until
+	}
+	return state
+}
+
+// Wake retrieves the channel signaled whenever do-not-disturb is toggled
+func (q *QuietHours) Wake() <-chan struct{} {
+	return q.wake
+}
+
+// until retrieves when the notifications due at now may be sent again given the quiet-hours windows,
+// adjacent windows are chained and a zero time is returned while do-not-disturb is enabled without an end
+func (q *QuietHours) until(windows []QuietWindow, now time.Time) (time.Time, bool) {
+	q.mu.Lock()
+	dnd, dndUntil := q.active(now), q.dndUntil
+	q.mu.Unlock()
+
+	if dnd && dndUntil.IsZero() {
+		return time.Time{}, true
+	}
+	end := now
+	for i := 0; i < maxQuietSteps; i++ {
+		moved := false
+		if dnd && end.Before(dndUntil) {
+			end, moved = dndUntil, true
+		}
+		for _, w := range windows {
+			if e, ok := w.end(end); ok {
+				end, moved = e, true
+			}
+		}
+		if !moved {
+			break
+		}
+	}
+	return end, end.After(now)
+}
+
+// reminderUntil retrieves when the notifications of a reminder due at now may be sent again,
+// its quiet hours without a time zone are evaluated in the reminder time zone
+// and urgent reminders are only held by do-not-disturb
+func (q *QuietHours) reminderUntil(reminder models.Reminder, now time.Time) (time.Time, bool) {
+	if reminderPriority(reminder) == models.PriorityUrgent {
+		return q.until(nil, now)
+	}
+	windows := q.serverWindows()
+	loc := reminderLocation(reminder)
+	for _, spec := range reminder.QuietHours {
+		if w, err := parseQuietWindow(spec, loc); err == nil {
+			windows = append(windows, w)
+		}
+	}
+	return q.until(windows, now)
+}
+
+// drops checks whether a reminder held by quiet hours or do-not-disturb is dropped instead
+func (q *QuietHours) drops(reminder models.Reminder) bool {
+	return q.dropLow && reminderPriority(reminder) == models.PriorityLow
+}
+
+// serverWindows retrieves a copy of the server quiet-hours windows
+func (q *QuietHours) serverWindows() []QuietWindow {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return append([]QuietWindow(nil), q.windows...)
+}
+
+// active checks whether do-not-disturb is in effect, it must be called with mu held
+func (q *QuietHours) active(now time.Time) bool {
+	return q.dnd && (q.dndUntil.IsZero() || now.Before(q.dndUntil))
+}
+
+// signal signals a do-not-disturb change without blocking
+func (q *QuietHours) signal() {
+	select {
+	case q.wake <- struct{}{}:
+	default:
+	}
+}
@@ -0,0 +1,227 @@
+package services
+
+import (
+	"github.com/muhtutorials/reminders_cli/server/models"
+	"testing"
+	"time"
+)
+
+func TestParseQuietWindow(t *testing.T) {
+	tests := []struct {
+		spec string
+		want string
+		err  bool
+	}{
+		{spec: "22:00-07:00", want: "22:00-07:00"},
+		{spec: "mon-fri 09:00-17:00", want: "mon,tue,wed,thu,fri 09:00-17:00"},
+		{spec: "fri-tue 00:00-24:00", want: "mon,tue,fri,sat,sun 00:00-24:00"},
+		{spec: "SUN,wed 12:30-13:00 Europe/Berlin", want: "wed,sun 12:30-13:00 Europe/Berlin"},
+		{spec: "mon-sun 08:00-09:00", want: "08:00-09:00"},
+		{spec: "", err: true},
+		{spec: "mon-fri", err: true},
+		{spec: "22:00", err: true},
+		{spec: "7:00-8:00", err: true},
+		{spec: "22:00-22:00", err: true},
+		{spec: "24:00-07:00", err: true},
+		{spec: "22:00-24:30", err: true},
+		{spec: "22:00-25:00", err: true},
+		{spec: "funday 22:00-07:00", err: true},
+		{spec: "mon-funday 22:00-07:00", err: true},
+		{spec: "mon fri 22:00-07:00", err: true},
+		{spec: "22:00-07:00 Mars/Olympus", err: true},
+		{spec: "22:00-07:00 UTC extra", err: true},
+	}
+	for _, tt := range tests {
+		w, err := ParseQuietWindow(tt.spec)
+		if tt.err {
+			if err == nil {
+				t.Errorf("%q: got window %s, want an error", tt.spec, w)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%q: %v", tt.spec, err)
+			continue
+		}
+		if got := w.String(); got != tt.want {
+			t.Errorf("%q: got %s, want %s", tt.spec, got, tt.want)
+		}
+	}
+}
+
+func TestQuietWindowEnd(t *testing.T) {
+	// 2030-01-01 is a tuesday
+	at := func(d, h, m int) time.Time {
+		return time.Date(2030, 1, d, h, m, 0, 0, time.UTC)
+	}
+	tests := []struct {
+		spec  string
+		at    time.Time
+		want  time.Time
+		quiet bool
+	}{
+		// overnight windows end the next day
+		{spec: "22:00-07:00", at: at(1, 22, 0), want: at(2, 7, 0), quiet: true},
+		{spec: "22:00-07:00", at: at(1, 23, 30), want: at(2, 7, 0), quiet: true},
+		{spec: "22:00-07:00", at: at(2, 3, 0), want: at(2, 7, 0), quiet: true},
+		{spec: "22:00-07:00", at: at(2, 7, 0)},
+		{spec: "22:00-07:00", at: at(2, 12, 0)},
+		// the days of an overnight window are the days it starts on
+		{spec: "fri-tue 22:00-07:00", at: at(2, 3, 0), want: at(2, 7, 0), quiet: true},
+		{spec: "fri-tue 22:00-07:00", at: at(2, 23, 0)},
+		{spec: "fri-tue 22:00-07:00", at: at(3, 3, 0)},
+		{spec: "fri-tue 22:00-07:00", at: at(5, 3, 0), want: at(5, 7, 0), quiet: true},
+		{spec: "fri-tue 22:00-07:00", at: at(7, 23, 0), want: at(8, 7, 0), quiet: true},
+		// whole days end at the next midnight
+		{spec: "00:00-24:00", at: at(1, 0, 0), want: at(2, 0, 0), quiet: true},
+		{spec: "00:00-24:00", at: at(1, 23, 59), want: at(2, 0, 0), quiet: true},
+		{spec: "sat,sun 00:00-24:00", at: at(4, 12, 0)},
+		{spec: "sat,sun 00:00-24:00", at: at(5, 12, 0), want: at(6, 0, 0), quiet: true},
+		// windows with a time zone are evaluated in it
+		{spec: "09:00-17:00 Asia/Tokyo", at: at(1, 0, 0), want: at(1, 8, 0), quiet: true},
+		{spec: "09:00-17:00 Asia/Tokyo", at: at(1, 8, 0)},
+	}
+	for _, tt := range tests {
+		w, err := parseQuietWindow(tt.spec, time.UTC)
+		if err != nil {
+			t.Fatalf("%q: %v", tt.spec, err)
+		}
+		got, quiet := w.end(tt.at)
+		if quiet != tt.quiet || !got.Equal(tt.want) {
+			t.Errorf("%q at %s: got %s (quiet %t), want %s (quiet %t)", tt.spec, tt.at, got, quiet, tt.want, tt.quiet)
+		}
+	}
+}
+
+func TestQuietHoursUntil(t *testing.T) {
+	at := func(d, h, m int) time.Time {
+		return time.Date(2030, 1, d, h, m, 0, 0, time.UTC)
+	}
+	tests := []struct {
+		name     string
+		specs    []string
+		dnd      bool
+		dndUntil time.Time
+		now      time.Time
+		want     time.Time
+		quiet    bool
+	}{
+		{
+			name:  "outside the windows",
+			specs: []string{"22:00-07:00"},
+			now:   at(1, 12, 0),
+		},
+		{
+			name:  "adjacent windows are chained",
+			specs: []string{"mon-fri 22:00-24:00", "00:00-07:00"},
+			now:   at(1, 23, 0),
+			want:  at(2, 7, 0),
+			quiet: true,
+		},
+		{
+			name:  "friday evening runs into the weekend",
+			specs: []string{"fri 18:00-24:00", "sat,sun 00:00-24:00"},
+			now:   at(4, 19, 0),
+			want:  at(7, 0, 0),
+			quiet: true,
+		},
+		{
+			name:  "windows covering the whole week end after the chaining bound",
+			specs: []string{"00:00-24:00"},
+			now:   at(1, 12, 0),
+			want:  at(1+maxQuietSteps, 0, 0),
+			quiet: true,
+		},
+		{
+			name:     "do-not-disturb runs into a window",
+			specs:    []string{"22:00-07:00"},
+			dnd:      true,
+			dndUntil: at(1, 23, 0),
+			now:      at(1, 12, 0),
+			want:     at(2, 7, 0),
+			quiet:    true,
+		},
+		{
+			name:     "a window runs into do-not-disturb",
+			specs:    []string{"22:00-07:00"},
+			dnd:      true,
+			dndUntil: at(2, 9, 0),
+			now:      at(1, 23, 0),
+			want:     at(2, 9, 0),
+			quiet:    true,
+		},
+		{
+			name:     "expired do-not-disturb",
+			dnd:      true,
+			dndUntil: at(1, 11, 0),
+			now:      at(1, 12, 0),
+		},
+		{
+			name:  "do-not-disturb without an end",
+			specs: []string{"22:00-07:00"},
+			dnd:   true,
+			now:   at(1, 12, 0),
+			quiet: true,
+		},
+	}
+	for _, tt := range tests {
+		var windows []QuietWindow
+		for _, spec := range tt.specs {
+			w, err := parseQuietWindow(spec, time.UTC)
+			if err != nil {
+				t.Fatalf("%s: %v", tt.name, err)
+			}
+			windows = append(windows, w)
+		}
+		q := NewQuietHours(windows, false, NewFakeClock(tt.now))
+		q.dnd, q.dndUntil = tt.dnd, tt.dndUntil
+		got, quiet := q.until(q.serverWindows(), tt.now)
+		if quiet != tt.quiet || (quiet && !got.Equal(tt.want)) {
+			t.Errorf("%s: got %s (quiet %t), want %s (quiet %t)", tt.name, got, quiet, tt.want, tt.quiet)
+		}
+	}
+}
+
+func TestQuietHoursReminderUntil(t *testing.T) {
+	now := time.Date(2030, 1, 1, 23, 0, 0, 0, time.UTC)
+	night, err := parseQuietWindow("22:00-07:00", time.UTC)
+	if err != nil {
+		t.Fatal(err)
+	}
+	q := NewQuietHours([]QuietWindow{night}, true, NewFakeClock(now))
+
+	// urgent reminders bypass the quiet-hours windows
+	urgent := models.Reminder{Priority: models.PriorityUrgent}
+	if until, quiet := q.reminderUntil(urgent, now); quiet {
+		t.Fatalf("got urgent reminder held until %s, want it sent", until)
+	}
+	// the quiet hours of a reminder without a time zone are evaluated in the reminder time zone
+	// and chained with the server ones
+	reminder := models.Reminder{QuietHours: []string{"07:00-18:00"}, TimeZone: "Asia/Tokyo"}
+	want := time.Date(2030, 1, 2, 9, 0, 0, 0, time.UTC)
+	if until, quiet := q.reminderUntil(reminder, now); !quiet || !until.Equal(want) {
+		t.Fatalf("got reminder held until %s (quiet %t), want %s", until, quiet, want)
+	}
+	if q.drops(reminder) || !q.drops(models.Reminder{Priority: models.PriorityLow}) {
+		t.Fatal("want only the low priority reminders to be dropped")
+	}
+
+	// do-not-disturb holds urgent reminders as well
+	dndUntil := now.Add(time.Hour)
+	state, err := q.SetDND(DNDBody{Enabled: true, Until: dndUntil})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !state.Enabled || state.Until == nil || !state.Until.Equal(dndUntil) {
+		t.Fatalf("got do-not-disturb state %+v, want it enabled until %s", state, dndUntil)
+	}
+	if until, quiet := q.reminderUntil(urgent, now); !quiet || !until.Equal(dndUntil) {
+		t.Fatalf("got urgent reminder held until %s (quiet %t), want %s", until, quiet, dndUntil)
+	}
+	if _, err := q.SetDND(DNDBody{Enabled: true, Until: now}); err == nil {
+		t.Fatal("got do-not-disturb enabled until now, want an error")
+	}
+	if state, _ := q.SetDND(DNDBody{}); state.Enabled {
+		t.Fatalf("got do-not-disturb state %+v, want it disabled", state)
+	}
+}
@@ -0,0 +1,365 @@
+package services
+
+import (
+	"fmt"
+	"github.com/muhtutorials/reminders_cli/server/models"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	FreqDaily   = "DAILY"
+	FreqWeekly  = "WEEKLY"
+	FreqMonthly = "MONTHLY"
+	FreqYearly  = "YEARLY"
+
+	// RepeatNone removes the recurrence of an edited reminder
+	RepeatNone = "none"
+
+	// maxRecurrencePeriods bounds the search of the next occurrence of rules which rarely match
+	maxRecurrencePeriods = 1000
+)
+
+var weekdays = map[string]time.Weekday{
+	"MO": time.Monday,
+	"TU": time.Tuesday,
+	"WE": time.Wednesday,
+	"TH": time.Thursday,
+	"FR": time.Friday,
+	"SA": time.Saturday,
+	"SU": time.Sunday,
+}
+
+// RecurrenceRule represents the supported subset of an RFC 5545 RRULE:
+// FREQ, INTERVAL, BYDAY (without ordinals), BYMONTHDAY, COUNT and UNTIL
+type RecurrenceRule struct {
+	Freq       string
+	Interval   int
+	ByDay      []time.Weekday
+	ByMonthDay []int
+	Count      int
+	Until      time.Time
+}
+
+// ParseRecurrenceRule parses an RRULE like "FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,FR;COUNT=10",
+// the "RRULE:" prefix is optional and a bare frequency like "daily" is accepted as a shortcut
+func ParseRecurrenceRule(rule string) (RecurrenceRule, error) {
+	return parseRecurrenceRule(rule, time.Local)
+}
+
+// parseRecurrenceRule parses an RRULE, a date-only UNTIL ends in the given time zone
+func parseRecurrenceRule(rule string, loc *time.Location) (RecurrenceRule, error) {
+	r := RecurrenceRule{Interval: 1}
+	rule = strings.TrimPrefix(strings.ToUpper(strings.TrimSpace(rule)), "RRULE:")
+	if !strings.Contains(rule, "=") {
+		rule = "FREQ=" + rule
+	}
+	for _, part := range strings.Split(rule, ";") {
+		if part == "" {
+			continue
+		}
+		key, value, ok := strings.Cut(part, "=")
+		if !ok {
+			return r, invalidRule("malformed part '%s'", part)
+		}
+		switch key {
+		case "FREQ":
+			switch value {
+			case FreqDaily, FreqWeekly, FreqMonthly, FreqYearly:
+				r.Freq = value
+			default:
+				return r, invalidRule("unsupported FREQ '%s'", value)
+			}
+		case "INTERVAL":
+			n, err := strconv.Atoi(value)
+			if err != nil || n < 1 {
+				return r, invalidRule("INTERVAL must be a positive integer")
+			}
+			r.Interval = n
+		case "BYDAY":
+			for _, day := range strings.Split(value, ",") {
+				wd, ok := weekdays[day]
+				if !ok {
+					return r, invalidRule("unsupported BYDAY '%s'", day)
+				}
+				r.ByDay = append(r.ByDay, wd)
+			}
+		case "BYMONTHDAY":
+			for _, day := range strings.Split(value, ",") {
+				n, err := strconv.Atoi(day)
+				if err != nil || n == 0 || n < -31 || n > 31 {
+					return r, invalidRule("BYMONTHDAY must be within [-31, -1] or [1, 31]")
+				}
+				r.ByMonthDay = append(r.ByMonthDay, n)
+			}
+		case "COUNT":
+			n, err := strconv.Atoi(value)
+			if err != nil || n < 1 {
+				return r, invalidRule("COUNT must be a positive integer")
+			}
+			r.Count = n
+		case "UNTIL":
+			until, err := parseUntil(value, loc)
+			if err != nil {
+				return r, invalidRule("UNTIL must be a date, a UTC date-time or an RFC3339 time")
+			}
+			r.Until = until
+		default:
+			return r, invalidRule("unsupported part '%s'", key)
+		}
+	}
+	if r.Freq == "" {
+		return r, invalidRule("FREQ is required")
+	}
+	if r.Count != 0 && !r.Until.IsZero() {
+		return r, invalidRule("COUNT and UNTIL cannot be both provided")
+	}
+	if r.Freq == FreqWeekly && len(r.ByMonthDay) > 0 {
+		return r, invalidRule("BYMONTHDAY cannot be used with FREQ=WEEKLY")
+	}
+	return r, nil
+}
+
+// String formats the rule as a normalized RRULE
+func (r RecurrenceRule) String() string {
+	parts := []string{"FREQ=" + r.Freq}
+	if r.Interval > 1 {
+		parts = append(parts, fmt.Sprintf("INTERVAL=%d", r.Interval))
+	}
+	if len(r.ByDay) > 0 {
+		var days []string
+		for _, wd := range r.ByDay {
+			for name, d := range weekdays {
+				if d == wd {
+					days = append(days, name)
+				}
+			}
+		}
+		parts = append(parts, "BYDAY="+strings.Join(days, ","))
+	}
+	if len(r.ByMonthDay) > 0 {
+		var days []string
+		for _, d := range r.ByMonthDay {
+			days = append(days, strconv.Itoa(d))
+		}
+		parts = append(parts, "BYMONTHDAY="+strings.Join(days, ","))
+	}
+	if r.Count > 0 {
+		parts = append(parts, fmt.Sprintf("COUNT=%d", r.Count))
+	}
+	if !r.Until.IsZero() {
+		parts = append(parts, "UNTIL="+r.Until.UTC().Format("20060102T150405Z"))
+	}
+	return strings.Join(parts, ";")
+}
+
+// Next computes the first occurrence strictly after prev, keeping the clock time and location of prev,
+// a zero time is returned when the rule has no more occurrences
+func (r RecurrenceRule) Next(prev time.Time) time.Time {
+	hour, minute, sec := prev.Clock()
+	at := func(day time.Time) time.Time {
+		return time.Date(day.Year(), day.Month(), day.Day(), hour, minute, sec, prev.Nanosecond(), prev.Location())
+	}
+	start := r.periodStart(prev)
+	for i := 0; i < maxRecurrencePeriods; i++ {
+		for _, day := range r.candidates(start, prev) {
+			t := at(day)
+			if !t.After(prev) {
+				continue
+			}
+			if !r.Until.IsZero() && t.After(r.Until) {
+				return time.Time{}
+			}
+			return t
+		}
+		start = r.nextPeriod(start)
+	}
+	return time.Time{}
+}
+
+// periodStart retrieves the midnight the FREQ period of t starts at (weeks start on monday)
+func (r RecurrenceRule) periodStart(t time.Time) time.Time {
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+	switch r.Freq {
+	case FreqWeekly:
+		return day.AddDate(0, 0, -((int(day.Weekday()) + 6) % 7))
+	case FreqMonthly, FreqYearly:
+		return day.AddDate(0, 0, 1-day.Day())
+	}
+	return day
+}
+
+// nextPeriod retrieves the start of the FREQ period INTERVAL periods after start
+func (r RecurrenceRule) nextPeriod(start time.Time) time.Time {
+	switch r.Freq {
+	case FreqWeekly:
+		return start.AddDate(0, 0, 7*r.Interval)
+	case FreqMonthly:
+		return start.AddDate(0, r.Interval, 0)
+	case FreqYearly:
+		return start.AddDate(r.Interval, 0, 0)
+	}
+	return start.AddDate(0, 0, r.Interval)
+}
+
+// candidates retrieves the sorted days of the period which match the rule,
+// prev provides the defaults for the weekday and the day of month
+func (r RecurrenceRule) candidates(start, prev time.Time) []time.Time {
+	var days []time.Time
+	switch r.Freq {
+	case FreqDaily:
+		days = []time.Time{start}
+	case FreqWeekly:
+		byDay := r.ByDay
+		if len(byDay) == 0 {
+			byDay = []time.Weekday{prev.Weekday()}
+		}
+		for i := 0; i < 7; i++ {
+			day := start.AddDate(0, 0, i)
+			if containsWeekday(byDay, day.Weekday()) {
+				days = append(days, day)
+			}
+		}
+	case FreqMonthly, FreqYearly:
+		// yearly periods start in the month of the previous occurrence
+		last := start.AddDate(0, 1, -1).Day()
+		switch {
+		case len(r.ByMonthDay) > 0:
+			for _, d := range r.ByMonthDay {
+				if d < 0 {
+					d = last + d + 1
+				}
+				if d >= 1 && d <= last {
+					days = append(days, start.AddDate(0, 0, d-1))
+				}
+			}
+		case len(r.ByDay) > 0:
+			for d := 1; d <= last; d++ {
+				days = append(days, start.AddDate(0, 0, d-1))
+			}
+		case prev.Day() <= last:
+			days = append(days, start.AddDate(0, 0, prev.Day()-1))
+		}
+		sort.Slice(days, func(i, j int) bool {
+			return days[i].Before(days[j])
+		})
+	}
+
+	var matching []time.Time
+	for _, day := range days {
+		if len(r.ByDay) > 0 && !containsWeekday(r.ByDay, day.Weekday()) {
+			continue
+		}
+		if r.Freq != FreqMonthly && r.Freq != FreqYearly && len(r.ByMonthDay) > 0 && !r.matchesMonthDay(day) {
+			continue
+		}
+		matching = append(matching, day)
+	}
+	return matching
+}
+
+// matchesMonthDay checks whether the day matches one of the BYMONTHDAY values
+func (r RecurrenceRule) matchesMonthDay(day time.Time) bool {
+	last := time.Date(day.Year(), day.Month()+1, 0, 0, 0, 0, 0, day.Location()).Day()
+	for _, d := range r.ByMonthDay {
+		if d == day.Day() || d < 0 && last+d+1 == day.Day() {
+			return true
+		}
+	}
+	return false
+}
+
+// nextOccurrence advances a recurring reminder to its next occurrence after now,
+// false is returned once the recurrence is exhausted
+func nextOccurrence(reminder models.Reminder, now time.Time) (models.Reminder, bool) {
+	if reminder.Recurrence == nil {
+		return reminder, false
+	}
+	rule, err := ParseRecurrenceRule(reminder.Recurrence.Rule)
+	if err != nil {
+		return reminder, false
+	}
+	recurrence := *reminder.Recurrence
+	// occurrences keep their clock time in the reminder time zone across DST transitions
+	next := recurrence.Current.In(reminderLocation(reminder))
+	for {
+		recurrence.Occurrence++
+		if rule.Count > 0 && recurrence.Occurrence >= rule.Count {
+			return reminder, false
+		}
+		next = rule.Next(next)
+		if next.IsZero() {
+			return reminder, false
+		}
+		// occurrences which passed while the reminder was not completed are skipped
+		if next.After(now) {
+			break
+		}
+	}
+	recurrence.Current = next
+	reminder.Recurrence = &recurrence
+	reminder.DueAt = next
+	return reminder, true
+}
+
+// latestOccurrence advances a recurring reminder to its latest occurrence due by now,
+// the reminder is returned unchanged when its current occurrence is already the latest one
+func latestOccurrence(reminder models.Reminder, now time.Time) models.Reminder {
+	if reminder.Recurrence == nil {
+		return reminder
+	}
+	rule, err := ParseRecurrenceRule(reminder.Recurrence.Rule)
+	if err != nil {
+		return reminder
+	}
+	recurrence := *reminder.Recurrence
+	recurrence.Current = recurrence.Current.In(reminderLocation(reminder))
+	for rule.Count == 0 || recurrence.Occurrence+1 < rule.Count {
+		next := rule.Next(recurrence.Current)
+		if next.IsZero() || next.After(now) {
+			break
+		}
+		recurrence.Occurrence++
+		recurrence.Current = next
+	}
+	if recurrence.Occurrence == reminder.Recurrence.Occurrence {
+		return reminder
+	}
+	reminder.Recurrence = &recurrence
+	reminder.DueAt = recurrence.Current
+	return reminder
+}
+
+func containsWeekday(days []time.Weekday, wd time.Weekday) bool {
+	for _, d := range days {
+		if d == wd {
+			return true
+		}
+	}
+	return false
+}
+
+// parseUntil parses an UNTIL value in one of the RFC 5545 or RFC3339 formats
+func parseUntil(value string, loc *time.Location) (time.Time, error) {
+	layouts := []string{"20060102T150405Z", "20060102", time.RFC3339}
+	var err error
+	for _, layout := range layouts {
+		var t time.Time
+		if t, err = time.Parse(layout, value); err == nil {
+			if layout == "20060102" {
+				// a date-only UNTIL includes the whole day
+				t = time.Date(t.Year(), t.Month(), t.Day(), 23, 59, 59, 0, loc)
+			}
+			return t, nil
+		}
+	}
+	return time.Time{}, err
+}
+
+func invalidRule(format string, args ...any) error {
+	return models.DataValidationError{
+		Message: "invalid repeat rule: " + fmt.Sprintf(format, args...),
+	}
+}
@@ -0,0 +1,159 @@
+package services
+
+import (
+	"github.com/muhtutorials/reminders_cli/server/models"
+	"testing"
+	"time"
+)
+
+func TestParseRecurrenceRule(t *testing.T) {
+	tests := []struct {
+		rule string
+		want string
+		err  bool
+	}{
+		{rule: "daily", want: "FREQ=DAILY"},
+		{rule: "RRULE:FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,FR;COUNT=10", want: "FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,FR;COUNT=10"},
+		{rule: "freq=monthly;bymonthday=-1", want: "FREQ=MONTHLY;BYMONTHDAY=-1"},
+		{rule: "FREQ=YEARLY;UNTIL=20301231T235959Z", want: "FREQ=YEARLY;UNTIL=20301231T235959Z"},
+		{rule: "FREQ=DAILY;INTERVAL=1", want: "FREQ=DAILY"},
+		{rule: "", err: true},
+		{rule: "hourly", err: true},
+		{rule: "FREQ=DAILY;INTERVAL=0", err: true},
+		{rule: "FREQ=WEEKLY;BYDAY=1MO", err: true},
+		{rule: "FREQ=MONTHLY;BYMONTHDAY=32", err: true},
+		{rule: "FREQ=MONTHLY;BYMONTHDAY=0", err: true},
+		{rule: "FREQ=DAILY;COUNT=2;UNTIL=20300101", err: true},
+		{rule: "FREQ=WEEKLY;BYMONTHDAY=1", err: true},
+		{rule: "FREQ=DAILY;BYSETPOS=1", err: true},
+		{rule: "FREQ=DAILY;COUNT", err: true},
+	}
+	for _, tt := range tests {
+		rule, err := parseRecurrenceRule(tt.rule, time.UTC)
+		if tt.err {
+			if err == nil {
+				t.Errorf("%q: got rule %s, want an error", tt.rule, rule)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%q: %v", tt.rule, err)
+			continue
+		}
+		if got := rule.String(); got != tt.want {
+			t.Errorf("%q: got %s, want %s", tt.rule, got, tt.want)
+		}
+	}
+}
+
+func TestRecurrenceRuleNext(t *testing.T) {
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Fatal(err)
+	}
+	utc := func(y int, m time.Month, d, h int) time.Time {
+		return time.Date(y, m, d, h, 0, 0, 0, time.UTC)
+	}
+	tests := []struct {
+		name string
+		rule string
+		from time.Time
+		want []time.Time
+	}{
+		{
+			name: "monthly on the 31st skips the shorter months",
+			rule: "FREQ=MONTHLY",
+			from: utc(2030, 1, 31, 9),
+			want: []time.Time{utc(2030, 3, 31, 9), utc(2030, 5, 31, 9), utc(2030, 7, 31, 9)},
+		},
+		{
+			name: "last day of the month",
+			rule: "FREQ=MONTHLY;BYMONTHDAY=-1",
+			from: utc(2030, 1, 31, 9),
+			want: []time.Time{utc(2030, 2, 28, 9), utc(2030, 3, 31, 9), utc(2030, 4, 30, 9)},
+		},
+		{
+			name: "every other week on monday and friday",
+			rule: "FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,FR",
+			from: utc(2030, 1, 7, 9), // a monday
+			want: []time.Time{utc(2030, 1, 11, 9), utc(2030, 1, 21, 9), utc(2030, 1, 25, 9), utc(2030, 2, 4, 9)},
+		},
+		{
+			name: "weekends of the month",
+			rule: "FREQ=MONTHLY;BYDAY=SA,SU",
+			from: utc(2030, 1, 27, 9), // a sunday
+			want: []time.Time{utc(2030, 2, 2, 9), utc(2030, 2, 3, 9)},
+		},
+		{
+			name: "leap day only repeats on leap years",
+			rule: "FREQ=YEARLY",
+			from: utc(2028, 2, 29, 9),
+			want: []time.Time{utc(2032, 2, 29, 9)},
+		},
+		{
+			name: "until is inclusive",
+			rule: "FREQ=DAILY;UNTIL=20300103T090000Z",
+			from: utc(2030, 1, 1, 9),
+			want: []time.Time{utc(2030, 1, 2, 9), utc(2030, 1, 3, 9), {}},
+		},
+		{
+			name: "the wall clock time is kept across DST transitions",
+			rule: "FREQ=DAILY",
+			from: time.Date(2030, 3, 9, 9, 0, 0, 0, newYork),
+			want: []time.Time{time.Date(2030, 3, 10, 9, 0, 0, 0, newYork), time.Date(2030, 3, 11, 9, 0, 0, 0, newYork)},
+		},
+	}
+	for _, tt := range tests {
+		rule, err := parseRecurrenceRule(tt.rule, time.UTC)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		prev := tt.from
+		for i, want := range tt.want {
+			got := rule.Next(prev)
+			if !got.Equal(want) {
+				t.Errorf("%s: occurrence %d: got %v, want %v", tt.name, i+1, got, want)
+				break
+			}
+			prev = got
+		}
+	}
+}
+
+func TestNextOccurrenceCount(t *testing.T) {
+	start := time.Date(2030, 1, 1, 9, 0, 0, 0, time.UTC)
+	reminder := models.Reminder{
+		DueAt:      start,
+		Recurrence: &models.Recurrence{Rule: "FREQ=DAILY;COUNT=3", Current: start},
+		TimeZone:   "UTC",
+	}
+	var due []time.Time
+	for {
+		next, ok := nextOccurrence(reminder, reminder.DueAt)
+		if !ok {
+			break
+		}
+		due = append(due, next.DueAt)
+		reminder = next
+	}
+	want := []time.Time{start.AddDate(0, 0, 1), start.AddDate(0, 0, 2)}
+	if len(due) != len(want) {
+		t.Fatalf("got occurrences %v after the first one, want %v", due, want)
+	}
+	for i := range want {
+		if !due[i].Equal(want[i]) {
+			t.Fatalf("got occurrences %v after the first one, want %v", due, want)
+		}
+	}
+
+	// occurrences which passed in the meantime are skipped
+	reminder = models.Reminder{
+		DueAt:      start,
+		Recurrence: &models.Recurrence{Rule: "FREQ=DAILY", Current: start},
+		TimeZone:   "UTC",
+	}
+	next, ok := nextOccurrence(reminder, start.AddDate(0, 0, 3).Add(time.Hour))
+	if !ok || !next.DueAt.Equal(start.AddDate(0, 0, 4)) || next.Recurrence.Occurrence != 4 {
+		t.Fatalf("got occurrence %d at %v, want the 4th one at %v", next.Recurrence.Occurrence, next.DueAt, start.AddDate(0, 0, 4))
+	}
+}
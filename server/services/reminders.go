@@ -4,7 +4,9 @@ import (
 	"fmt"
 	"github.com/muhtutorials/reminders_cli/server/models"
 	"log"
+	"sort"
 	"strings"
+	"sync"
 	"time"
 )
 
@@ -21,6 +23,16 @@ func (rm RemindersMap) flatten(id int) (int, models.Reminder) {
 	return index, reminder
 }
 
+// clone makes a copy of the reminders map which can be read without holding the service lock
+func (rm RemindersMap) clone() RemindersMap {
+	c := make(RemindersMap, len(rm))
+	for id := range rm {
+		index, reminder := rm.flatten(id)
+		c[id] = map[int]models.Reminder{index: reminder}
+	}
+	return c
+}
+
 type ReminderRepository interface {
 	Save([]models.Reminder) (int, error)
 	Filter(filterFn func(reminder models.Reminder) bool) (RemindersMap, error)
@@ -33,49 +45,123 @@ type Snapshot struct {
 	Uncompleted RemindersMap
 }
 
-// Reminders represents the Reminders service
+// Reminders represents the Reminders service,
+// all the access to the in memory state and its search index is synchronized by mu
 type Reminders struct {
-	repo     ReminderRepository
-	Snapshot Snapshot
+	mu            sync.RWMutex
+	repo          ReminderRepository
+	scheduler     *Scheduler
+	channels      *Channels
+	events        EventPublisher
+	catchUpPolicy CatchUpPolicy
+	clock         Clock
+	state         Snapshot
+	search        *searchIndex
+	lastIndex     int
 }
 
-func NewReminders(repo ReminderRepository) *Reminders {
+func NewReminders(
+	repo ReminderRepository,
+	scheduler *Scheduler,
+	channels *Channels,
+	events EventPublisher,
+	catchUpPolicy CatchUpPolicy,
+	clock Clock,
+) *Reminders {
 	return &Reminders{
-		repo: repo,
-		Snapshot: Snapshot{
+		repo:          repo,
+		scheduler:     scheduler,
+		channels:      channels,
+		events:        events,
+		catchUpPolicy: catchUpPolicy,
+		clock:         clock,
+		state: Snapshot{
 			All:         RemindersMap{},
 			Uncompleted: RemindersMap{},
 		},
+		search: newSearchIndex(),
 	}
 }
 
-// Populate populates the reminders service internal state with data from db file
-func (rs Reminders) Populate() error {
+// Populate populates the reminders service internal state with data from db file,
+// the active reminders which came due while the server was down are handled by the catch-up policy,
+// pending holds the ids of the reminders the outbox still has deliveries for
+func (rs *Reminders) Populate(pending map[int]bool) error {
 	all, err := rs.repo.Filter(nil)
 	if err != nil {
 		return models.WrapError("could not get all reminders", err)
 	}
-	uncompleted, err := rs.repo.Filter(func(r models.Reminder) bool {
-		return r.ModifiedAt.Add(r.Duration).UnixNano() > time.Now().UnixNano()
-	})
-	if err != nil {
-		return models.WrapError("could not get uncompleted reminders", err)
+	now := rs.clock.Now()
+	uncompleted := RemindersMap{}
+	var scheduled []int
+	var missed []indexedReminder
+	for id := range all {
+		index, reminder := all.flatten(id)
+		// records saved before due_at was introduced are due relatively to their last modification
+		if reminder.DueAt.IsZero() {
+			reminder.DueAt = reminder.ModifiedAt.Add(reminder.Duration)
+		}
+		if reminder.Status == "" {
+			reminder.Status = legacyStatus(reminder, now)
+		}
+		reminder = localize(reminder)
+		all[id] = map[int]models.Reminder{index: reminder}
+		switch {
+		case !reminder.Status.Active():
+			continue
+		case reminder.Status == models.StatusFiring && pending[id]:
+			// the outbox resumes the delivery of the reminders which were firing,
+			// the others were waiting to be retried and are scheduled or caught up like the pending ones
+		case reminder.DueAt.After(now):
+			scheduled = append(scheduled, id)
+		default:
+			missed = append(missed, indexedReminder{index: index, reminder: reminder})
+			continue
+		}
+		uncompleted[id] = map[int]models.Reminder{index: reminder}
+	}
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+	rs.state.All = all
+	rs.state.Uncompleted = uncompleted
+	rs.search = newSearchIndex()
+	rs.lastIndex = len(all) - 1
+	for id := range all {
+		index, reminder := all.flatten(id)
+		if index > rs.lastIndex {
+			rs.lastIndex = index
+		}
+		rs.search.index(reminder)
+	}
+	for _, id := range scheduled {
+		_, reminder := uncompleted.flatten(id)
+		rs.scheduler.Schedule(id, reminder.DueAt)
 	}
-	rs.Snapshot.All = all
-	rs.Snapshot.Uncompleted = uncompleted
+	rs.catchUp(missed, now)
 	return nil
 }
 
-// ReminderCreateBody represents the model for creating a reminder
+// ReminderCreateBody represents the model for creating a reminder,
+// the reminder is due either after Duration or at DueAt and optionally repeats by the Repeat RRULE,
+// Actions are the custom buttons shown on its notifications, Channel overrides the default channel,
+// its notifications are held during the QuietHours windows, TimeZone is the IANA time zone
+// its due time and recurrence are evaluated in, Tags categorize it and Priority is normal when empty
 type ReminderCreateBody struct {
 	Title       string
 	Message     string
 	Duration    time.Duration
+	DueAt       time.Time
 	RetryPeriod time.Duration
+	Repeat      string
+	Actions     []models.Action
+	Channel     string
+	QuietHours  []string
+	TimeZone    string
+	Tags        []string
+	Priority    string
 }
 
-func (rs Reminders) Create(body ReminderCreateBody) (models.Reminder, error) {
-	nextID := rs.repo.NextID()
+func (rs *Reminders) Create(body ReminderCreateBody) (models.Reminder, error) {
 	if body.Title == "" {
 		err := models.DataValidationError{
 			Message: "title cannot be empty",
@@ -88,9 +174,14 @@ func (rs Reminders) Create(body ReminderCreateBody) (models.Reminder, error) {
 		}
 		return models.Reminder{}, err
 	}
-	if body.Duration == 0 {
+	now := rs.clock.Now()
+	dueAt, duration, err := resolveDueAt(body.Duration, body.DueAt, "due_at", now)
+	if err != nil {
+		return models.Reminder{}, err
+	}
+	if dueAt.IsZero() {
 		err := models.DataValidationError{
-			Message: "duration cannot be 0",
+			Message: "either duration or due_at must be provided",
 		}
 		return models.Reminder{}, err
 	}
@@ -100,39 +191,105 @@ func (rs Reminders) Create(body ReminderCreateBody) (models.Reminder, error) {
 		}
 		return models.Reminder{}, err
 	}
+	if err := validateActions(body.Actions); err != nil {
+		return models.Reminder{}, err
+	}
+	if err := validateChannel(rs.channels, body.Channel); err != nil {
+		return models.Reminder{}, err
+	}
+	quietHours, err := normalizeQuietHours(body.QuietHours)
+	if err != nil {
+		return models.Reminder{}, err
+	}
+	if len(quietHours) == 0 {
+		quietHours = nil
+	}
+	tags, err := normalizeTags(body.Tags)
+	if err != nil {
+		return models.Reminder{}, err
+	}
+	if len(tags) == 0 {
+		tags = nil
+	}
+	priority := models.PriorityNormal
+	if body.Priority != "" {
+		if priority, err = ParsePriority(body.Priority); err != nil {
+			return models.Reminder{}, err
+		}
+	}
+	loc, err := loadTimeZone(body.TimeZone)
+	if err != nil {
+		return models.Reminder{}, err
+	}
+	dueAt = dueAt.In(loc)
+	var recurrence *models.Recurrence
+	if body.Repeat != "" {
+		if recurrence, err = newRecurrence(body.Repeat, dueAt); err != nil {
+			return models.Reminder{}, err
+		}
+	}
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
 	reminder := models.Reminder{
-		ID:          nextID,
+		ID:          rs.repo.NextID(),
 		Title:       body.Title,
 		Message:     body.Message,
-		Duration:    body.Duration,
+		Duration:    duration,
+		DueAt:       dueAt,
 		RetryPeriod: body.RetryPeriod,
-		CreatedAt:   time.Now(),
-		ModifiedAt:  time.Now(),
+		Recurrence:  recurrence,
+		Actions:     body.Actions,
+		Channel:     body.Channel,
+		QuietHours:  quietHours,
+		TimeZone:    timeZoneName(loc),
+		Tags:        tags,
+		Priority:    priority,
+		Status:      models.StatusPending,
+		CreatedAt:   now,
+		ModifiedAt:  now,
 	}
-	index := len(rs.Snapshot.All)
-	rs.Snapshot.All[reminder.ID] = map[int]models.Reminder{index: reminder}
-	rs.Snapshot.Uncompleted[reminder.ID] = map[int]models.Reminder{index: reminder}
+	rs.lastIndex++
+	rs.store(rs.lastIndex, reminder)
+	rs.scheduler.Schedule(reminder.ID, reminder.DueAt)
+	rs.publish(EventReminderCreated, reminder)
 	return reminder, nil
 }
 
+// ReminderEditBody represents the model for editing a reminder,
+// only a new Duration or DueAt reschedules the reminder and RepeatNone removes its recurrence,
+// non-nil Actions replace the custom buttons of the reminder and an empty list removes them,
+// ChannelDefault makes the reminder use the default channel again,
+// non-nil QuietHours replace its quiet-hours windows and non-nil Tags its tags, an empty list removes them,
+// and TimeZoneLocal makes the reminder use the server time zone again
 type ReminderEditBody struct {
 	ID          int
 	Title       string
 	Message     string
 	Duration    time.Duration
+	DueAt       time.Time
 	RetryPeriod time.Duration
+	Repeat      string
+	Actions     []models.Action
+	Channel     string
+	QuietHours  []string
+	TimeZone    string
+	Tags        []string
+	Priority    string
 }
 
-func (rs Reminders) Edit(reminderBody ReminderEditBody) (models.Reminder, error) {
-	_, ok := rs.Snapshot.All[reminderBody.ID]
-	if !ok {
-		err := models.NotFoundError{
-			Message: fmt.Sprintf("could not find reminder with id: %d", reminderBody.ID),
-		}
+func (rs *Reminders) Edit(reminderBody ReminderEditBody) (models.Reminder, error) {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+	index, reminder, err := rs.find(reminderBody.ID)
+	if err != nil {
+		return models.Reminder{}, err
+	}
+	now := rs.clock.Now()
+	dueAt, duration, err := resolveDueAt(reminderBody.Duration, reminderBody.DueAt, "due_at", now)
+	if err != nil {
 		return models.Reminder{}, err
 	}
 	changed := false
-	index, reminder := rs.Snapshot.All.flatten(reminderBody.ID)
 	if strings.TrimSpace(reminderBody.Title) != "" {
 		reminder.Title = reminderBody.Title
 		changed = true
@@ -141,41 +298,127 @@ func (rs Reminders) Edit(reminderBody ReminderEditBody) (models.Reminder, error)
 		reminder.Message = reminderBody.Message
 		changed = true
 	}
-	if reminderBody.Duration != 0 {
-		reminder.Duration = reminderBody.Duration
+	rescheduled := !dueAt.IsZero()
+	if rescheduled {
+		reminder.Duration = duration
+		reminder.DueAt = dueAt
+		changed = true
+	}
+	if reminderBody.TimeZone != "" {
+		loc, err := loadTimeZone(reminderBody.TimeZone)
+		if err != nil {
+			return models.Reminder{}, err
+		}
+		reminder.TimeZone = timeZoneName(loc)
+		changed = true
+	}
+	reminder = localize(reminder)
+	if reminderBody.Priority != "" {
+		if reminder.Priority, err = ParsePriority(reminderBody.Priority); err != nil {
+			return models.Reminder{}, err
+		}
 		changed = true
 	}
 	if reminderBody.RetryPeriod != 0 {
 		reminder.RetryPeriod = reminderBody.RetryPeriod
 		changed = true
 	}
+	if reminderBody.Actions != nil {
+		if err := validateActions(reminderBody.Actions); err != nil {
+			return models.Reminder{}, err
+		}
+		reminder.Actions = nil
+		if len(reminderBody.Actions) > 0 {
+			reminder.Actions = reminderBody.Actions
+		}
+		changed = true
+	}
+	if reminderBody.QuietHours != nil {
+		quietHours, err := normalizeQuietHours(reminderBody.QuietHours)
+		if err != nil {
+			return models.Reminder{}, err
+		}
+		reminder.QuietHours = nil
+		if len(quietHours) > 0 {
+			reminder.QuietHours = quietHours
+		}
+		changed = true
+	}
+	if reminderBody.Tags != nil {
+		tags, err := normalizeTags(reminderBody.Tags)
+		if err != nil {
+			return models.Reminder{}, err
+		}
+		reminder.Tags = nil
+		if len(tags) > 0 {
+			reminder.Tags = tags
+		}
+		changed = true
+	}
+	switch {
+	case strings.EqualFold(reminderBody.Channel, ChannelDefault):
+		reminder.Channel = ""
+		changed = true
+	case reminderBody.Channel != "":
+		if err := validateChannel(rs.channels, reminderBody.Channel); err != nil {
+			return models.Reminder{}, err
+		}
+		reminder.Channel = reminderBody.Channel
+		changed = true
+	}
+	switch {
+	case strings.EqualFold(reminderBody.Repeat, RepeatNone):
+		reminder.Recurrence = nil
+		changed = true
+	case reminderBody.Repeat != "":
+		if reminder.Recurrence, err = newRecurrence(reminderBody.Repeat, reminder.DueAt); err != nil {
+			return models.Reminder{}, err
+		}
+		changed = true
+	case rescheduled && reminder.Recurrence != nil:
+		// the rescheduled due time becomes the current occurrence of the series
+		recurrence := *reminder.Recurrence
+		recurrence.Current = reminder.DueAt
+		reminder.Recurrence = &recurrence
+	}
 	if !changed {
 		err := models.FormatValidationError{
-			Message: "body must contain at least 1 of: 'title', 'message', 'duration', 'retryPeriod'",
+			Message: "body must contain at least 1 of: 'title', 'message', 'duration', 'due_at', 'retry_period', 'repeat', 'actions', 'channel', 'quiet_hours', 'time_zone', 'tags', 'priority'",
 		}
 		return models.Reminder{}, err
 	}
-	reminder.ModifiedAt = time.Now()
-	rs.Snapshot.All[reminder.ID] = map[int]models.Reminder{index: reminder}
-	if reminder.ModifiedAt.UnixNano() < time.Now().Add(reminderBody.Duration).UnixNano() {
-		rs.Snapshot.Uncompleted[reminder.ID] = map[int]models.Reminder{index: reminder}
-	} else {
-		delete(rs.Snapshot.Uncompleted, reminder.ID)
+	reminder.ModifiedAt = now
+	if rescheduled {
+		// a new due time makes the reminder notified again
+		if err := transition(&reminder, models.StatusPending, now); err != nil {
+			return models.Reminder{}, err
+		}
+		rs.scheduler.Schedule(reminder.ID, reminder.DueAt)
 	}
+	rs.store(index, reminder)
+	rs.publish(EventReminderEdited, reminder)
 	return reminder, nil
 }
 
-func (rs Reminders) Fetch(ids []int) ([]models.Reminder, error) {
+// Fetch fetches the reminders with the given ids, only the ones matching the tag filter are retrieved
+func (rs *Reminders) Fetch(ids []int, tags TagFilter) ([]models.Reminder, error) {
+	if err := tags.validate(); err != nil {
+		return []models.Reminder{}, err
+	}
+	rs.mu.RLock()
+	defer rs.mu.RUnlock()
 	reminders := make([]models.Reminder, 0)
 	var notFound []int
 	for _, id := range ids {
-		_, ok := rs.Snapshot.All[id]
+		_, ok := rs.state.All[id]
 		if !ok {
 			notFound = append(notFound, id)
 			continue
 		}
-		_, reminder := rs.Snapshot.All.flatten(id)
-		reminders = append(reminders, reminder)
+		_, reminder := rs.state.All.flatten(id)
+		if tags.matches(reminder.Tags) {
+			reminders = append(reminders, reminder)
+		}
 	}
 	if len(notFound) > 0 {
 		err := models.NotFoundError{
@@ -186,10 +429,12 @@ func (rs Reminders) Fetch(ids []int) ([]models.Reminder, error) {
 	return reminders, nil
 }
 
-func (rs Reminders) Delete(ids []int) error {
+func (rs *Reminders) Delete(ids []int) error {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
 	var notFound []int
 	for _, id := range ids {
-		_, ok := rs.Snapshot.All[id]
+		_, ok := rs.state.All[id]
 		if !ok {
 			notFound = append(notFound, id)
 		}
@@ -200,18 +445,39 @@ func (rs Reminders) Delete(ids []int) error {
 		}
 	}
 	for _, id := range ids {
-		delete(rs.Snapshot.All, id)
-		delete(rs.Snapshot.Uncompleted, id)
+		_, reminder := rs.state.All.flatten(id)
+		rs.publish(EventReminderDeleted, reminder)
+		delete(rs.state.All, id)
+		delete(rs.state.Uncompleted, id)
+		rs.search.remove(id)
+		rs.scheduler.Cancel(id)
 	}
 	return nil
 }
 
-func (rs Reminders) save() error {
-	reminders := make([]models.Reminder, len(rs.Snapshot.All))
-	for _, remindersMap := range rs.Snapshot.All {
-		for i, reminder := range remindersMap {
-			reminders[i] = reminder
+// save persists a point in time copy of all the reminders in the order they were added
+func (rs *Reminders) save() error {
+	rs.mu.RLock()
+	type indexed struct {
+		index    int
+		reminder models.Reminder
+	}
+	records := make([]indexed, 0, len(rs.state.All))
+	for id := range rs.state.All {
+		index, reminder := rs.state.All.flatten(id)
+		records = append(records, indexed{index: index, reminder: reminder})
+	}
+	rs.mu.RUnlock()
+
+	sort.Slice(records, func(i, j int) bool {
+		if records[i].index != records[j].index {
+			return records[i].index < records[j].index
 		}
+		return records[i].reminder.ID < records[j].reminder.ID
+	})
+	reminders := make([]models.Reminder, len(records))
+	for i, record := range records {
+		reminders[i] = record.reminder
 	}
 	n, err := rs.repo.Save(reminders)
 	if err != nil {
@@ -223,35 +489,234 @@ func (rs Reminders) save() error {
 	return nil
 }
 
-// GetSnapshot fetches the current service snapshot
-func (rs Reminders) snapshot() Snapshot {
-	return rs.Snapshot
+// find fetches a reminder and its index by id
+func (rs *Reminders) find(id int) (int, models.Reminder, error) {
+	if _, ok := rs.state.All[id]; !ok {
+		err := models.NotFoundError{
+			Message: fmt.Sprintf("could not find reminder with id: %d", id),
+		}
+		return 0, models.Reminder{}, err
+	}
+	index, reminder := rs.state.All.flatten(id)
+	return index, reminder, nil
+}
+
+// store stores a reminder in the snapshot keeping the uncompleted reminders in sync with its status
+// and the search index in sync with its title and message, scheduling an active reminder is left to the caller
+func (rs *Reminders) store(index int, reminder models.Reminder) {
+	rs.state.All[reminder.ID] = map[int]models.Reminder{index: reminder}
+	rs.search.index(reminder)
+	if reminder.Status.Active() {
+		rs.state.Uncompleted[reminder.ID] = map[int]models.Reminder{index: reminder}
+		return
+	}
+	delete(rs.state.Uncompleted, reminder.ID)
+	rs.scheduler.Cancel(reminder.ID)
+}
+
+// newRecurrence validates an RRULE and starts a recurrence with dueAt as its first occurrence,
+// dueAt is expected in the reminder time zone
+func newRecurrence(repeat string, dueAt time.Time) (*models.Recurrence, error) {
+	rule, err := parseRecurrenceRule(repeat, dueAt.Location())
+	if err != nil {
+		return nil, err
+	}
+	if !rule.Until.IsZero() && rule.Until.Before(dueAt) {
+		err := models.DataValidationError{
+			Message: "repeat rule UNTIL cannot be before the reminder due time",
+		}
+		return nil, err
+	}
+	return &models.Recurrence{Rule: rule.String(), Current: dueAt}, nil
 }
 
-// snapshotGrooming clears the current snapshot from notified reminders
-func (rs Reminders) snapshotGrooming(notifiedReminders ...models.Reminder) {
+// resolveDueAt resolves the due time of a reminder given either a relative duration
+// or an absolute time named atName, zero values are returned if none of them are provided
+func resolveDueAt(duration time.Duration, dueAt time.Time, atName string, now time.Time) (time.Time, time.Duration, error) {
+	switch {
+	case duration != 0 && !dueAt.IsZero():
+		err := models.DataValidationError{
+			Message: "only one of duration or " + atName + " can be provided",
+		}
+		return time.Time{}, 0, err
+	case duration < 0:
+		err := models.DataValidationError{
+			Message: "duration cannot be negative",
+		}
+		return time.Time{}, 0, err
+	case duration > 0:
+		return now.Add(duration), duration, nil
+	case !dueAt.IsZero():
+		if !dueAt.After(now) {
+			err := models.DataValidationError{
+				Message: atName + " must be in the future",
+			}
+			return time.Time{}, 0, err
+		}
+		return dueAt, dueAt.Sub(now), nil
+	}
+	return time.Time{}, 0, nil
+}
+
+// active retrieves a reminder if it is still waiting to be notified or acknowledged
+func (rs *Reminders) active(id int) (models.Reminder, bool) {
+	rs.mu.RLock()
+	defer rs.mu.RUnlock()
+	if _, ok := rs.state.Uncompleted[id]; !ok {
+		return models.Reminder{}, false
+	}
+	_, reminder := rs.state.Uncompleted.flatten(id)
+	return reminder, true
+}
+
+// fire moves a due reminder to the firing status right before it is notified
+func (rs *Reminders) fire(id int) (models.Reminder, bool) {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+	if _, ok := rs.state.Uncompleted[id]; !ok {
+		return models.Reminder{}, false
+	}
+	index, reminder := rs.state.All.flatten(id)
+	if err := transition(&reminder, models.StatusFiring, rs.clock.Now()); err != nil {
+		log.Printf("could not fire reminder: %v", err)
+		return models.Reminder{}, false
+	}
+	rs.store(index, reminder)
+	rs.publish(EventReminderFired, reminder)
+	return reminder, true
+}
+
+// fired retrieves a reminder if it is still firing
+func (rs *Reminders) fired(id int) (models.Reminder, bool) {
+	rs.mu.RLock()
+	defer rs.mu.RUnlock()
+	_, reminder, ok := rs.firing(id)
+	return reminder, ok
+}
+
+// firing fetches a reminder by id if it is still firing,
+// it might have been deleted or rescheduled while it was being notified
+func (rs *Reminders) firing(id int) (int, models.Reminder, bool) {
+	if _, ok := rs.state.All[id]; !ok {
+		return 0, models.Reminder{}, false
+	}
+	index, reminder := rs.state.All.flatten(id)
+	return index, reminder, reminder.Status == models.StatusFiring
+}
+
+// snapshotGrooming completes the notified reminders and clears them from the uncompleted ones,
+// recurring reminders are advanced to their next occurrence instead
+func (rs *Reminders) snapshotGrooming(notifiedReminders ...models.Reminder) {
 	if len(notifiedReminders) > 0 {
 		log.Printf("snapshot grooming: %d record(s)", len(notifiedReminders))
 	}
-	for _, reminder := range notifiedReminders {
-		delete(rs.Snapshot.Uncompleted, reminder.ID)
-		reminder.Duration = -time.Hour
-		index, _ := rs.Snapshot.All.flatten(reminder.ID)
-		rs.Snapshot.All[reminder.ID] = map[int]models.Reminder{index: reminder}
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+	now := rs.clock.Now()
+	for _, notified := range notifiedReminders {
+		index, reminder, ok := rs.firing(notified.ID)
+		if !ok {
+			continue
+		}
+		rs.complete(index, reminder, now)
 	}
 }
 
-// retry retries a reminder by resetting its duration
-func (rs Reminders) retry(reminder models.Reminder) {
-	reminder.ModifiedAt = time.Now()
-	reminder.Duration = reminder.RetryPeriod
+// retry retries a firing reminder by postponing its due time by the retry period scaled by its priority
+func (rs *Reminders) retry(notified models.Reminder) {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+	index, reminder, ok := rs.firing(notified.ID)
+	if !ok {
+		return
+	}
+	period := retryPeriod(reminder)
+	reminder.DueAt = rs.clock.Now().Add(period)
 
 	log.Printf(
 		"retrying record with id: %d after %v",
 		reminder.ID,
-		reminder.Duration.String(),
+		period.String(),
 	)
-	index, _ := rs.Snapshot.All.flatten(reminder.ID)
-	rs.Snapshot.All[reminder.ID] = map[int]models.Reminder{index: reminder}
-	rs.Snapshot.Uncompleted[reminder.ID] = map[int]models.Reminder{index: reminder}
+	rs.store(index, reminder)
+	rs.scheduler.Schedule(reminder.ID, reminder.DueAt)
+}
+
+// drop skips the current occurrence of a low-priority reminder due during quiet hours or do-not-disturb,
+// recurring reminders move to their next occurrence and the others are marked as missed without being scheduled
+func (rs *Reminders) drop(id int) {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+	index, reminder, err := rs.find(id)
+	if err != nil || !reminder.Status.Active() {
+		return
+	}
+	now := rs.clock.Now()
+	if next, ok := nextOccurrence(reminder, now); ok {
+		log.Printf("low priority reminder with id: %d was dropped by quiet hours, it repeats at %v", id, next.DueAt)
+		_ = transition(&next, models.StatusPending, now)
+		rs.store(index, next)
+		rs.scheduler.Schedule(next.ID, next.DueAt)
+		return
+	}
+	log.Printf("low priority reminder with id: %d was dropped by quiet hours and marked as missed", id)
+	_ = transition(&reminder, models.StatusPending, now)
+	reminder.Missed = true
+	rs.store(index, reminder)
+}
+
+// fail marks a firing reminder which cannot be delivered as failed
+func (rs *Reminders) fail(notified models.Reminder) {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+	index, reminder, ok := rs.firing(notified.ID)
+	if !ok {
+		return
+	}
+	log.Printf("reminder with id: %d failed", reminder.ID)
+	_ = transition(&reminder, models.StatusFailed, rs.clock.Now())
+	rs.store(index, reminder)
+	rs.publish(EventReminderFailed, reminder)
+}
+
+// snoozeFiring postpones a firing reminder by the given duration as requested from the notification,
+// a non-positive duration falls back to the retry period
+func (rs *Reminders) snoozeFiring(notified models.Reminder, d time.Duration) {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+	index, reminder, ok := rs.firing(notified.ID)
+	if !ok {
+		return
+	}
+	if d <= 0 {
+		d = reminder.RetryPeriod
+	}
+	now := rs.clock.Now()
+	_ = transition(&reminder, models.StatusSnoozed, now)
+	reminder.DueAt = now.Add(d)
+	log.Printf("reminder with id: %d was snoozed for %v", reminder.ID, d)
+	rs.store(index, reminder)
+	rs.scheduler.Schedule(reminder.ID, reminder.DueAt)
+	rs.publish(EventReminderSnoozed, reminder)
+}
+
+// cancelFiring cancels a firing reminder as requested from the notification
+func (rs *Reminders) cancelFiring(notified models.Reminder) {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+	index, reminder, ok := rs.firing(notified.ID)
+	if !ok {
+		return
+	}
+	log.Printf("reminder with id: %d was cancelled", reminder.ID)
+	_ = transition(&reminder, models.StatusCancelled, rs.clock.Now())
+	rs.store(index, reminder)
+	rs.publish(EventReminderCancelled, reminder)
+}
+
+// publish publishes a reminder lifecycle event if an event publisher is configured
+func (rs *Reminders) publish(event string, reminder models.Reminder) {
+	if rs.events != nil {
+		rs.events.Publish(event, reminder)
+	}
 }
@@ -0,0 +1,126 @@
+package services
+
+import (
+	"fmt"
+	"github.com/muhtutorials/reminders_cli/server/models"
+	"sync"
+	"testing"
+	"time"
+)
+
+func newTestReminders(clock Clock) *Reminders {
+	return NewReminders(&memoryRepository{}, NewScheduler(), NewChannels(""), nil, CatchUpFire, clock)
+}
+
+func TestRemindersConcurrentAccess(t *testing.T) {
+	const (
+		workers    = 8
+		iterations = 50
+	)
+	clock := NewFakeClock(time.Date(2030, 1, 1, 9, 0, 0, 0, time.UTC))
+	rs := newTestReminders(clock)
+
+	var wg sync.WaitGroup
+	errs := make(chan error, workers)
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < iterations; i++ {
+				reminder, err := rs.Create(ReminderCreateBody{
+					Title:       fmt.Sprintf("worker %d reminder %d", w, i),
+					Message:     "concurrent",
+					Duration:    time.Minute,
+					RetryPeriod: time.Minute,
+					Tags:        []string{fmt.Sprintf("worker-%d", w)},
+				})
+				if err != nil {
+					errs <- err
+					return
+				}
+				_, err = rs.Edit(ReminderEditBody{ID: reminder.ID, Title: "edited " + reminder.Title})
+				if err != nil {
+					errs <- err
+					return
+				}
+				if _, ok := rs.fire(reminder.ID); !ok {
+					errs <- fmt.Errorf("reminder with id: %d did not fire", reminder.ID)
+					return
+				}
+				if fired, ok := rs.fired(reminder.ID); ok {
+					rs.snapshotGrooming(fired)
+				}
+				if _, err := rs.Search("edited", 5); err != nil {
+					errs <- err
+					return
+				}
+				if _, err := rs.List(ReminderQuery{Status: string(models.StatusCompleted)}); err != nil {
+					errs <- err
+					return
+				}
+				if err := rs.save(); err != nil {
+					errs <- err
+					return
+				}
+				if i%2 == 0 {
+					if err := rs.Delete([]int{reminder.ID}); err != nil {
+						errs <- err
+						return
+					}
+				}
+			}
+		}(w)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Fatal(err)
+	}
+
+	rs.mu.RLock()
+	defer rs.mu.RUnlock()
+	if want := workers * iterations / 2; len(rs.state.All) != want {
+		t.Fatalf("got %d reminders, want %d", len(rs.state.All), want)
+	}
+	if len(rs.state.Uncompleted) != 0 {
+		t.Fatalf("got %d uncompleted reminders, want none", len(rs.state.Uncompleted))
+	}
+	if len(rs.search.docs) != len(rs.state.All) {
+		t.Fatalf("search index holds %d reminders, want %d", len(rs.search.docs), len(rs.state.All))
+	}
+	for id := range rs.state.All {
+		_, reminder := rs.state.All.flatten(id)
+		if reminder.Status != models.StatusCompleted {
+			t.Fatalf("reminder with id: %d is %s, want completed", id, reminder.Status)
+		}
+	}
+}
+
+func TestRemindersEditTimeZone(t *testing.T) {
+	rs := newTestReminders(NewFakeClock(time.Date(2030, 1, 1, 9, 0, 0, 0, time.UTC)))
+	reminder, err := rs.Create(ReminderCreateBody{
+		Title:       "call",
+		Message:     "call home",
+		Duration:    time.Hour,
+		RetryPeriod: time.Minute,
+		TimeZone:    "Asia/Tokyo",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reminder.TimeZone != "Asia/Tokyo" {
+		t.Fatalf("got time zone %q, want Asia/Tokyo", reminder.TimeZone)
+	}
+
+	// the server time zone is stored as an empty name
+	reminder, err = rs.Edit(ReminderEditBody{ID: reminder.ID, TimeZone: TimeZoneLocal})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reminder.TimeZone != "" {
+		t.Fatalf("got time zone %q, want the server time zone", reminder.TimeZone)
+	}
+	if _, err := rs.Edit(ReminderEditBody{ID: reminder.ID, TimeZone: "Mars/Olympus"}); err == nil {
+		t.Fatal("got an unknown time zone accepted, want an error")
+	}
+}
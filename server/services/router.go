@@ -0,0 +1,328 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"github.com/muhtutorials/reminders_cli/server/models"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// DefaultNotifierTarget names the target of a notifier URL provided without a name
+const DefaultNotifierTarget = "default"
+
+// NotifierTarget represents a notifier service instance, targets with a lower priority are tried first
+type NotifierTarget struct {
+	Name     string
+	URL      string
+	Priority int
+}
+
+// ParseNotifierTarget parses a target formatted as [name[@priority]=]url
+func ParseNotifierTarget(s string) (NotifierTarget, error) {
+	target := NotifierTarget{Name: DefaultNotifierTarget, URL: s}
+	if name, url, ok := strings.Cut(s, "="); ok && !strings.Contains(name, "/") {
+		target.Name, target.URL = name, url
+		if name, priority, ok := strings.Cut(name, "@"); ok {
+			p, err := strconv.Atoi(priority)
+			if err != nil {
+				return target, fmt.Errorf("invalid priority '%s' of notifier target '%s'", priority, name)
+			}
+			target.Name, target.Priority = name, p
+		}
+	}
+	if target.Name == "" || target.URL == "" {
+		return target, fmt.Errorf("notifier target must be formatted as [name[@priority]=]url")
+	}
+	return target, nil
+}
+
+// RoutingRule routes the reminders whose Field matches Value to the Targets,
+// Op is either "=" (equal) or "~" (contains, case-insensitive) and a FanOut rule notifies all the targets
+// at once instead of failing over from one to the next
+type RoutingRule struct {
+	Field   string
+	Op      string
+	Value   string
+	Targets []string
+	FanOut  bool
+}
+
+// ParseRoutingRule parses a rule formatted as field=value:targets or field~value:targets,
+// targets are either separated by "," (failover in the given order) or by "+" (fan out),
+// "*" matches all the reminders
+func ParseRoutingRule(s string) (RoutingRule, error) {
+	var rule RoutingRule
+	i := strings.LastIndex(s, ":")
+	if i < 0 {
+		return rule, fmt.Errorf("routing rule '%s' must be formatted as field=value:targets", s)
+	}
+	match, targets := s[:i], s[i+1:]
+	if match != "*" {
+		j := strings.IndexAny(match, "=~")
+		if j <= 0 {
+			return rule, fmt.Errorf("routing rule '%s' must match field=value or field~value", s)
+		}
+		rule.Field, rule.Op, rule.Value = strings.ToLower(match[:j]), match[j:j+1], match[j+1:]
+		if _, ok := reminderField(models.Reminder{}, rule.Field); !ok {
+			return rule, fmt.Errorf("routing rule '%s' matches unknown field '%s'", s, rule.Field)
+		}
+	}
+	sep := ","
+	if strings.Contains(targets, "+") {
+		sep, rule.FanOut = "+", true
+	}
+	for _, target := range strings.Split(targets, sep) {
+		if target = strings.TrimSpace(target); target != "" {
+			rule.Targets = append(rule.Targets, target)
+		}
+	}
+	if len(rule.Targets) == 0 {
+		return rule, fmt.Errorf("routing rule '%s' has no targets", s)
+	}
+	return rule, nil
+}
+
+// matches checks whether a reminder satisfies the rule, a tag rule is satisfied by any of the reminder tags
+func (r RoutingRule) matches(reminder models.Reminder) bool {
+	switch r.Field {
+	case "":
+		return true
+	case "tag":
+		for _, tag := range reminder.Tags {
+			if r.matchesValue(tag) {
+				return true
+			}
+		}
+		return false
+	}
+	value, _ := reminderField(reminder, r.Field)
+	return r.matchesValue(value)
+}
+
+// matchesValue checks whether a field value satisfies the rule
+func (r RoutingRule) matchesValue(value string) bool {
+	if r.Op == "~" {
+		return strings.Contains(strings.ToLower(value), strings.ToLower(r.Value))
+	}
+	return strings.EqualFold(value, r.Value)
+}
+
+// reminderField retrieves the string value of a reminder field routing rules can match
+func reminderField(reminder models.Reminder, field string) (string, bool) {
+	switch field {
+	case "id":
+		return strconv.Itoa(reminder.ID), true
+	case "title":
+		return reminder.Title, true
+	case "message":
+		return reminder.Message, true
+	case "status":
+		return string(reminder.Status), true
+	case "tag":
+		return strings.Join(reminder.Tags, ","), true
+	case "priority":
+		return string(reminderPriority(reminder)), true
+	case "repeat":
+		if reminder.Recurrence == nil {
+			return "", true
+		}
+		return reminder.Recurrence.Rule, true
+	}
+	return "", false
+}
+
+// routedTarget represents a notifier target and the channel delivering to it
+type routedTarget struct {
+	NotifierTarget
+	channel Channel
+}
+
+// NotifierRouter represents a channel notifying several notifier targets,
+// the first matching routing rule selects the targets of a reminder and reminders matching no rule
+// fail over through all the targets by priority
+type NotifierRouter struct {
+	targets []routedTarget
+	rules   []RoutingRule
+}
+
+func NewNotifierRouter(rules []RoutingRule) *NotifierRouter {
+	return &NotifierRouter{
+		rules: rules,
+	}
+}
+
+// Add adds a target delivered through the given channel
+func (r *NotifierRouter) Add(target NotifierTarget, channel Channel) {
+	r.targets = append(r.targets, routedTarget{NotifierTarget: target, channel: channel})
+	sort.SliceStable(r.targets, func(i, j int) bool {
+		return r.targets[i].Priority < r.targets[j].Priority
+	})
+}
+
+// Validate checks that the target names are unique and that the routing rules only use known targets
+func (r *NotifierRouter) Validate() error {
+	names := map[string]bool{}
+	for _, target := range r.targets {
+		if names[target.Name] {
+			return fmt.Errorf("notifier target '%s' is duplicated", target.Name)
+		}
+		names[target.Name] = true
+	}
+	for _, rule := range r.rules {
+		for _, name := range rule.Targets {
+			if !names[name] {
+				return fmt.Errorf("routing rule uses unknown notifier target '%s'", name)
+			}
+		}
+	}
+	return nil
+}
+
+// Len retrieves the number of targets
+func (r *NotifierRouter) Len() int {
+	return len(r.targets)
+}
+
+// Notify notifies a reminder delivery through the targets selected by the routing rules
+func (r *NotifierRouter) Notify(delivery models.Delivery, reminder models.Reminder) (NotificationResponse, error) {
+	targets, fanOut := r.route(reminder)
+	if fanOut {
+		return r.fanOut(targets, delivery, reminder)
+	}
+	return r.failover(targets, delivery, reminder)
+}
+
+// breakers retrieves the states of the circuit breakers of the targets
+func (r *NotifierRouter) breakers() map[string]BreakerState {
+	breakers := map[string]BreakerState{}
+	for _, target := range r.targets {
+		if breaker, ok := target.channel.(*CircuitBreaker); ok {
+			breakers[ChannelNotifier+"/"+target.Name] = breaker.State()
+		}
+	}
+	return breakers
+}
+
+// stop stops the circuit breakers of the targets
+func (r *NotifierRouter) stop() {
+	for _, target := range r.targets {
+		if breaker, ok := target.channel.(*CircuitBreaker); ok {
+			breaker.Stop()
+		}
+	}
+}
+
+// route selects the targets of a reminder
+func (r *NotifierRouter) route(reminder models.Reminder) ([]routedTarget, bool) {
+	for _, rule := range r.rules {
+		if !rule.matches(reminder) {
+			continue
+		}
+		var targets []routedTarget
+		for _, name := range rule.Targets {
+			for _, target := range r.targets {
+				if target.Name == name {
+					targets = append(targets, target)
+				}
+			}
+		}
+		return targets, rule.FanOut
+	}
+	return r.targets, false
+}
+
+// failover notifies the targets one after another until one of them succeeds,
+// the delivery is held only when all the targets have their circuit breaker open
+func (r *NotifierRouter) failover(targets []routedTarget, delivery models.Delivery, reminder models.Reminder) (NotificationResponse, error) {
+	var errs []error
+	var held *CircuitOpenError
+	for _, target := range targets {
+		res, err := target.channel.Notify(delivery, reminder)
+		if err == nil {
+			return res, nil
+		}
+		var circuitOpen CircuitOpenError
+		if errors.As(err, &circuitOpen) {
+			if held == nil || circuitOpen.RetryAt.Before(held.RetryAt) {
+				held = &circuitOpen
+			}
+		}
+		errs = append(errs, fmt.Errorf("%s target: %w", target.Name, err))
+	}
+	if held != nil && allHeld(errs) {
+		return NotificationResponse{}, *held
+	}
+	return NotificationResponse{}, routingError(errs)
+}
+
+// fanOut notifies all the targets at once, the first user action replied right away wins
+// and the delivery is pending if any target accepted it
+func (r *NotifierRouter) fanOut(targets []routedTarget, delivery models.Delivery, reminder models.Reminder) (NotificationResponse, error) {
+	type outcome struct {
+		res NotificationResponse
+		err error
+	}
+	outcomes := make([]outcome, len(targets))
+	var wg sync.WaitGroup
+	for i, target := range targets {
+		wg.Add(1)
+		go func(i int, target routedTarget) {
+			defer wg.Done()
+			res, err := target.channel.Notify(delivery, reminder)
+			if err != nil {
+				err = fmt.Errorf("%s target: %w", target.Name, err)
+			}
+			outcomes[i] = outcome{res: res, err: err}
+		}(i, target)
+	}
+	wg.Wait()
+
+	var errs []error
+	pending := false
+	for _, o := range outcomes {
+		switch {
+		case o.err != nil:
+			errs = append(errs, o.err)
+		case !o.res.pending:
+			return o.res, nil
+		default:
+			pending = true
+		}
+	}
+	if pending {
+		return NotificationResponse{pending: true}, nil
+	}
+	return NotificationResponse{}, routingError(errs)
+}
+
+// allHeld checks whether all the targets failed because their circuit breaker is open
+func allHeld(errs []error) bool {
+	for _, err := range errs {
+		var circuitOpen CircuitOpenError
+		if !errors.As(err, &circuitOpen) {
+			return false
+		}
+	}
+	return true
+}
+
+// routingError joins the errors of all the targets, the notification is rejected only if all the targets rejected it
+func routingError(errs []error) error {
+	if len(errs) == 0 {
+		return fmt.Errorf("%w: no notifier target matches the reminder", errNotificationRejected)
+	}
+	rejected := true
+	for _, err := range errs {
+		if !errors.Is(err, errNotificationRejected) {
+			rejected = false
+		}
+	}
+	err := errors.Join(errs...)
+	if rejected {
+		return fmt.Errorf("%w: %v", errNotificationRejected, err)
+	}
+	return fmt.Errorf("all notifier targets failed: %v", err)
+}
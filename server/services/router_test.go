@@ -0,0 +1,193 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"github.com/muhtutorials/reminders_cli/server/models"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParseNotifierTarget(t *testing.T) {
+	tests := []struct {
+		s    string
+		want NotifierTarget
+		err  bool
+	}{
+		{s: "http://localhost:5000", want: NotifierTarget{Name: DefaultNotifierTarget, URL: "http://localhost:5000"}},
+		{s: "primary=http://a:5000", want: NotifierTarget{Name: "primary", URL: "http://a:5000"}},
+		{s: "backup@2=http://b:5000", want: NotifierTarget{Name: "backup", URL: "http://b:5000", Priority: 2}},
+		{s: "backup@-1=http://b:5000", want: NotifierTarget{Name: "backup", URL: "http://b:5000", Priority: -1}},
+		// an equal sign after a slash belongs to the url
+		{s: "http://a:5000/?key=value", want: NotifierTarget{Name: DefaultNotifierTarget, URL: "http://a:5000/?key=value"}},
+		{s: "", err: true},
+		{s: "=http://a:5000", err: true},
+		{s: "@1=http://a:5000", err: true},
+		{s: "primary=", err: true},
+		{s: "backup@high=http://b:5000", err: true},
+	}
+	for _, tt := range tests {
+		got, err := ParseNotifierTarget(tt.s)
+		if tt.err {
+			if err == nil {
+				t.Errorf("%q: got target %+v, want an error", tt.s, got)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%q: %v", tt.s, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("%q: got %+v, want %+v", tt.s, got, tt.want)
+		}
+	}
+}
+
+func TestParseRoutingRule(t *testing.T) {
+	tests := []struct {
+		s    string
+		want RoutingRule
+		err  bool
+	}{
+		{s: "tag=work:primary", want: RoutingRule{Field: "tag", Op: "=", Value: "work", Targets: []string{"primary"}}},
+		{s: "Title~Rent:primary,backup", want: RoutingRule{Field: "title", Op: "~", Value: "Rent", Targets: []string{"primary", "backup"}}},
+		{s: "*:a+b", want: RoutingRule{Targets: []string{"a", "b"}, FanOut: true}},
+		{s: "priority=urgent:a + b", want: RoutingRule{Field: "priority", Op: "=", Value: "urgent", Targets: []string{"a", "b"}, FanOut: true}},
+		// the targets follow the last colon
+		{s: "message=at 9:30:backup", want: RoutingRule{Field: "message", Op: "=", Value: "at 9:30", Targets: []string{"backup"}}},
+		{s: "tag=work", err: true},
+		{s: "=work:primary", err: true},
+		{s: "work:primary", err: true},
+		{s: "url=http://a:primary", err: true},
+		{s: "tag=work:", err: true},
+		{s: "tag=work: , ", err: true},
+		{s: "*:", err: true},
+	}
+	for _, tt := range tests {
+		got, err := ParseRoutingRule(tt.s)
+		if tt.err {
+			if err == nil {
+				t.Errorf("%q: got rule %+v, want an error", tt.s, got)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%q: %v", tt.s, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%q: got %+v, want %+v", tt.s, got, tt.want)
+		}
+	}
+}
+
+// newTestRouter creates a router with the given rules over the targets t0, t1, ... tried in that order
+func newTestRouter(t *testing.T, rules []string, channels ...*scriptedChannel) *NotifierRouter {
+	t.Helper()
+	var parsed []RoutingRule
+	for _, s := range rules {
+		rule, err := ParseRoutingRule(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		parsed = append(parsed, rule)
+	}
+	router := NewNotifierRouter(parsed)
+	for i, channel := range channels {
+		router.Add(NotifierTarget{Name: fmt.Sprintf("t%d", i), Priority: i}, channel)
+	}
+	if err := router.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	return router
+}
+
+func TestNotifierRouterFailover(t *testing.T) {
+	clock := NewFakeClock(time.Date(2030, 1, 1, 9, 0, 0, 0, time.UTC))
+	primary := &scriptedChannel{errs: []error{errors.New("connection refused")}, actions: []string{"", ActionClicked}, clock: clock}
+	backup := &scriptedChannel{actions: []string{ActionDismissed, ActionDismissed}, clock: clock}
+	router := newTestRouter(t, []string{"tag=oncall:t1"}, primary, backup)
+	notify := func(reminder models.Reminder) string {
+		t.Helper()
+		res, err := router.Notify(models.Delivery{ID: "d"}, reminder)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return res.result.Action
+	}
+
+	// the backup replies when the primary target fails
+	if action := notify(models.Reminder{ID: 1}); action != ActionDismissed {
+		t.Fatalf("got action %s, want the backup to reply", action)
+	}
+	// the primary target is tried first again
+	if action := notify(models.Reminder{ID: 1}); action != ActionClicked {
+		t.Fatalf("got action %s, want the primary target to reply", action)
+	}
+	// the routing rule skips the primary target
+	if action := notify(models.Reminder{ID: 2, Tags: []string{"oncall"}}); action != ActionDismissed {
+		t.Fatalf("got action %s, want the routed target to reply", action)
+	}
+	if got, want := []int{len(primary.notifications()), len(backup.notifications())}, []int{2, 2}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %v notifications per target, want %v", got, want)
+	}
+}
+
+func TestNotifierRouterErrors(t *testing.T) {
+	clock := NewFakeClock(time.Date(2030, 1, 1, 9, 0, 0, 0, time.UTC))
+	rejected := fmt.Errorf("%w: bad request", errNotificationRejected)
+	reminder := models.Reminder{ID: 1}
+
+	// the notification is rejected only when every target rejects it
+	router := newTestRouter(t, nil,
+		&scriptedChannel{errs: []error{rejected}, clock: clock},
+		&scriptedChannel{errs: []error{rejected}, clock: clock},
+	)
+	if _, err := router.Notify(models.Delivery{}, reminder); !errors.Is(err, errNotificationRejected) {
+		t.Fatalf("got error %v, want a rejected notification", err)
+	}
+	router = newTestRouter(t, nil,
+		&scriptedChannel{errs: []error{rejected}, clock: clock},
+		&scriptedChannel{errs: []error{errors.New("timeout")}, clock: clock},
+	)
+	if _, err := router.Notify(models.Delivery{}, reminder); err == nil || errors.Is(err, errNotificationRejected) {
+		t.Fatalf("got error %v, want a retryable error", err)
+	}
+
+	// the delivery is held until the earliest probe when every circuit breaker is open
+	retryAt := clock.Now().Add(time.Minute)
+	router = newTestRouter(t, nil,
+		&scriptedChannel{errs: []error{CircuitOpenError{Channel: "t0", RetryAt: retryAt.Add(time.Minute)}}, clock: clock},
+		&scriptedChannel{errs: []error{CircuitOpenError{Channel: "t1", RetryAt: retryAt}}, clock: clock},
+	)
+	var circuitOpen CircuitOpenError
+	if _, err := router.Notify(models.Delivery{}, reminder); !errors.As(err, &circuitOpen) || !circuitOpen.RetryAt.Equal(retryAt) {
+		t.Fatalf("got error %v, want the delivery held until %v", err, retryAt)
+	}
+
+	// a router without targets rejects the notification
+	router = newTestRouter(t, nil)
+	if _, err := router.Notify(models.Delivery{}, reminder); !errors.Is(err, errNotificationRejected) {
+		t.Fatalf("got error %v, want a rejected notification", err)
+	}
+}
+
+func TestNotifierRouterFanOut(t *testing.T) {
+	clock := NewFakeClock(time.Date(2030, 1, 1, 9, 0, 0, 0, time.UTC))
+	failing := &scriptedChannel{errs: []error{errors.New("connection refused")}, clock: clock}
+	replying := &scriptedChannel{actions: []string{ActionDismissed}, clock: clock}
+	router := newTestRouter(t, []string{"priority=urgent:t0+t1"}, failing, replying)
+
+	res, err := router.Notify(models.Delivery{}, models.Reminder{ID: 1, Priority: models.PriorityUrgent})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.result.Action != ActionDismissed {
+		t.Fatalf("got action %s, want the replying target to win", res.result.Action)
+	}
+	if len(failing.notifications()) != 1 || len(replying.notifications()) != 1 {
+		t.Fatal("want every target to be notified")
+	}
+}
@@ -0,0 +1,140 @@
+package services
+
+import (
+	"container/heap"
+	"sync"
+	"time"
+)
+
+// scheduleItem represents a reminder waiting in the scheduler queue
+type scheduleItem struct {
+	id    int
+	at    time.Time
+	index int
+}
+
+// scheduleQueue implements heap.Interface ordered by the due time of the items
+type scheduleQueue []*scheduleItem
+
+func (q scheduleQueue) Len() int {
+	return len(q)
+}
+
+func (q scheduleQueue) Less(i, j int) bool {
+	if q[i].at.Equal(q[j].at) {
+		return q[i].id < q[j].id
+	}
+	return q[i].at.Before(q[j].at)
+}
+
+func (q scheduleQueue) Swap(i, j int) {
+	q[i], q[j] = q[j], q[i]
+	q[i].index = i
+	q[j].index = j
+}
+
+func (q *scheduleQueue) Push(x any) {
+	item := x.(*scheduleItem)
+	item.index = len(*q)
+	*q = append(*q, item)
+}
+
+func (q *scheduleQueue) Pop() any {
+	old := *q
+	n := len(old)
+	item := old[n-1]
+	old[n-1] = nil
+	item.index = -1
+	*q = old[:n-1]
+	return item
+}
+
+// Scheduler represents a priority queue of reminder ids keyed on their due time,
+// every change of the queue wakes up the goroutine waiting for the next due reminder
+type Scheduler struct {
+	mu    sync.Mutex
+	queue scheduleQueue
+	items map[int]*scheduleItem
+	wake  chan struct{}
+}
+
+func NewScheduler() *Scheduler {
+	return &Scheduler{
+		items: map[int]*scheduleItem{},
+		wake:  make(chan struct{}, 1),
+	}
+}
+
+// Schedule schedules a reminder at the given time, rescheduling it if it is already queued,
+// the monotonic clock reading is stripped so the due times are compared on the wall clock
+func (s *Scheduler) Schedule(id int, at time.Time) {
+	at = at.Round(0)
+	s.mu.Lock()
+	if item, ok := s.items[id]; ok {
+		item.at = at
+		heap.Fix(&s.queue, item.index)
+	} else {
+		item := &scheduleItem{id: id, at: at}
+		heap.Push(&s.queue, item)
+		s.items[id] = item
+	}
+	s.mu.Unlock()
+	s.signal()
+}
+
+// Cancel removes a reminder from the queue
+func (s *Scheduler) Cancel(id int) {
+	s.mu.Lock()
+	item, ok := s.items[id]
+	if ok {
+		heap.Remove(&s.queue, item.index)
+		delete(s.items, id)
+	}
+	s.mu.Unlock()
+	if ok {
+		s.signal()
+	}
+}
+
+// Next retrieves the due time of the earliest scheduled reminder
+func (s *Scheduler) Next() (time.Time, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.queue) == 0 {
+		return time.Time{}, false
+	}
+	return s.queue[0].at, true
+}
+
+// Due pops the ids of all the reminders due at or before now
+func (s *Scheduler) Due(now time.Time) []int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var ids []int
+	for len(s.queue) > 0 && !s.queue[0].at.After(now) {
+		item := heap.Pop(&s.queue).(*scheduleItem)
+		delete(s.items, item.id)
+		ids = append(ids, item.id)
+	}
+	return ids
+}
+
+// Len retrieves the number of scheduled reminders
+func (s *Scheduler) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.queue)
+}
+
+// Wake retrieves the channel signaled on every change of the queue
+func (s *Scheduler) Wake() <-chan struct{} {
+	return s.wake
+}
+
+// signal signals a queue change without blocking, pending signals are coalesced
+func (s *Scheduler) signal() {
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
@@ -0,0 +1,267 @@
+package services
+
+import (
+	"fmt"
+	"github.com/muhtutorials/reminders_cli/server/models"
+	"math"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+const (
+	// titleWeight boosts the terms found in a reminder title over the ones found in its message
+	titleWeight = 3
+
+	defaultSearchLimit = 20
+)
+
+// SearchResult represents a reminder matching a search query along with its relevance score
+type SearchResult struct {
+	Reminder models.Reminder `json:"reminder"`
+	Score    float64         `json:"score"`
+}
+
+// Search searches the titles and messages of the reminders, the best matches first,
+// the query terms must all match, a term ending with "*" matches as a prefix
+// and the terms between double quotes match as a phrase
+func (rs *Reminders) Search(q string, limit int) ([]SearchResult, error) {
+	if limit < 0 || limit > maxListLimit {
+		return nil, models.DataValidationError{
+			Message: fmt.Sprintf("limit must be between 1 and %d", maxListLimit),
+		}
+	}
+	if limit == 0 {
+		limit = defaultSearchLimit
+	}
+	clauses := parseSearchQuery(q)
+	if len(clauses) == 0 {
+		return nil, models.DataValidationError{Message: "search query cannot be empty"}
+	}
+
+	rs.mu.RLock()
+	defer rs.mu.RUnlock()
+	scores := rs.search.match(clauses)
+	results := make([]SearchResult, 0, len(scores))
+	for id, score := range scores {
+		if _, ok := rs.state.All[id]; !ok {
+			continue
+		}
+		_, reminder := rs.state.All.flatten(id)
+		results = append(results, SearchResult{Reminder: reminder, Score: math.Round(score*1000) / 1000})
+	}
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].Score != results[j].Score {
+			return results[i].Score > results[j].Score
+		}
+		return results[i].Reminder.ID < results[j].Reminder.ID
+	})
+	if len(results) > limit {
+		results = results[:limit]
+	}
+	return results, nil
+}
+
+// searchClause represents a single query term, a prefix or a phrase of several terms
+type searchClause struct {
+	terms  []string
+	prefix bool
+}
+
+// parseSearchQuery splits a query into its clauses
+func parseSearchQuery(q string) []searchClause {
+	var clauses []searchClause
+	for i, part := range strings.Split(q, `"`) {
+		// the odd parts are between double quotes
+		if i%2 == 1 {
+			if terms := tokenize(part); len(terms) > 0 {
+				clauses = append(clauses, searchClause{terms: terms})
+			}
+			continue
+		}
+		for _, word := range strings.Fields(part) {
+			terms := tokenize(word)
+			for _, term := range terms {
+				clauses = append(clauses, searchClause{terms: []string{term}})
+			}
+			if len(terms) > 0 && strings.HasSuffix(word, "*") {
+				clauses[len(clauses)-1].prefix = true
+			}
+		}
+	}
+	return clauses
+}
+
+// tokenize splits a text into lowercase terms of letters and digits
+func tokenize(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// searchDoc represents the indexed text of a reminder, the message terms follow the title ones
+type searchDoc struct {
+	title     string
+	message   string
+	titleLen  int
+	positions map[string][]int
+}
+
+// searchIndex represents an inverted index of the reminder titles and messages,
+// it maps every term to the reminders containing it and keeps the terms sorted for prefix lookups,
+// it is synchronized by the mutex of the Reminders service
+type searchIndex struct {
+	docs     map[int]*searchDoc
+	postings map[string]map[int]bool
+	terms    []string
+}
+
+func newSearchIndex() *searchIndex {
+	return &searchIndex{
+		docs:     map[int]*searchDoc{},
+		postings: map[string]map[int]bool{},
+	}
+}
+
+// index indexes the title and message of a reminder, replacing its previous ones
+func (idx *searchIndex) index(reminder models.Reminder) {
+	if doc, ok := idx.docs[reminder.ID]; ok {
+		if doc.title == reminder.Title && doc.message == reminder.Message {
+			return
+		}
+		idx.remove(reminder.ID)
+	}
+	titleTerms := tokenize(reminder.Title)
+	doc := &searchDoc{
+		title:     reminder.Title,
+		message:   reminder.Message,
+		titleLen:  len(titleTerms),
+		positions: map[string][]int{},
+	}
+	// a gap keeps the phrases from spanning the title and the message
+	terms := append(append(titleTerms, ""), tokenize(reminder.Message)...)
+	for pos, term := range terms {
+		if term == "" {
+			continue
+		}
+		doc.positions[term] = append(doc.positions[term], pos)
+		ids, ok := idx.postings[term]
+		if !ok {
+			ids = map[int]bool{}
+			idx.postings[term] = ids
+			i := sort.SearchStrings(idx.terms, term)
+			idx.terms = append(idx.terms, "")
+			copy(idx.terms[i+1:], idx.terms[i:])
+			idx.terms[i] = term
+		}
+		ids[reminder.ID] = true
+	}
+	idx.docs[reminder.ID] = doc
+}
+
+// remove removes a reminder from the index
+func (idx *searchIndex) remove(id int) {
+	doc, ok := idx.docs[id]
+	if !ok {
+		return
+	}
+	for term := range doc.positions {
+		delete(idx.postings[term], id)
+		if len(idx.postings[term]) == 0 {
+			delete(idx.postings, term)
+			i := sort.SearchStrings(idx.terms, term)
+			idx.terms = append(idx.terms[:i], idx.terms[i+1:]...)
+		}
+	}
+	delete(idx.docs, id)
+}
+
+// match scores the reminders matching all the clauses,
+// every occurrence of a clause counts its inverse document frequency and occurrences in the title count more
+func (idx *searchIndex) match(clauses []searchClause) map[int]float64 {
+	var scores map[int]float64
+	for _, clause := range clauses {
+		clauseScores := idx.matchClause(clause)
+		if scores == nil {
+			scores = clauseScores
+			continue
+		}
+		for id, score := range scores {
+			if s, ok := clauseScores[id]; ok {
+				scores[id] = score + s
+			} else {
+				delete(scores, id)
+			}
+		}
+	}
+	return scores
+}
+
+// matchClause scores the reminders matching a single clause
+func (idx *searchIndex) matchClause(clause searchClause) map[int]float64 {
+	scores := map[int]float64{}
+	if clause.prefix {
+		term := clause.terms[0]
+		for i := sort.SearchStrings(idx.terms, term); i < len(idx.terms) && strings.HasPrefix(idx.terms[i], term); i++ {
+			idx.scoreTerm(scores, idx.terms[i])
+		}
+		return scores
+	}
+	if len(clause.terms) == 1 {
+		idx.scoreTerm(scores, clause.terms[0])
+		return scores
+	}
+	idf := 0.0
+	for _, term := range clause.terms {
+		idf += idx.idf(term)
+	}
+	for id := range idx.postings[clause.terms[0]] {
+		doc := idx.docs[id]
+		for _, start := range doc.positions[clause.terms[0]] {
+			if doc.hasPhrase(clause.terms, start) {
+				scores[id] += doc.weight(start) * idf
+			}
+		}
+	}
+	return scores
+}
+
+// scoreTerm adds the score of a term to the reminders containing it
+func (idx *searchIndex) scoreTerm(scores map[int]float64, term string) {
+	idf := idx.idf(term)
+	for id := range idx.postings[term] {
+		doc := idx.docs[id]
+		for _, pos := range doc.positions[term] {
+			scores[id] += doc.weight(pos) * idf
+		}
+	}
+}
+
+// idf retrieves the inverse document frequency of a term, rare terms weigh more
+func (idx *searchIndex) idf(term string) float64 {
+	return math.Log(1 + float64(len(idx.docs))/float64(len(idx.postings[term])+1))
+}
+
+// hasPhrase checks whether the terms follow each other from the given position
+func (doc *searchDoc) hasPhrase(terms []string, start int) bool {
+	for offset, term := range terms[1:] {
+		if !containsInt(doc.positions[term], start+offset+1) {
+			return false
+		}
+	}
+	return true
+}
+
+// weight retrieves the weight of a term occurrence depending on whether it is in the title
+func (doc *searchDoc) weight(pos int) float64 {
+	if pos < doc.titleLen {
+		return titleWeight
+	}
+	return 1
+}
+
+// containsInt checks whether a sorted slice contains n
+func containsInt(sorted []int, n int) bool {
+	i := sort.SearchInts(sorted, n)
+	return i < len(sorted) && sorted[i] == n
+}
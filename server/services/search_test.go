@@ -0,0 +1,125 @@
+package services
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+)
+
+func TestParseSearchQuery(t *testing.T) {
+	tests := []struct {
+		q    string
+		want []searchClause
+	}{
+		{q: "Rent", want: []searchClause{{terms: []string{"rent"}}}},
+		{q: "pay  rent", want: []searchClause{{terms: []string{"pay"}}, {terms: []string{"rent"}}}},
+		{q: "land*", want: []searchClause{{terms: []string{"land"}, prefix: true}}},
+		{q: "e-mail*", want: []searchClause{{terms: []string{"e"}}, {terms: []string{"mail"}, prefix: true}}},
+		{q: `"pay the rent" car`, want: []searchClause{{terms: []string{"pay", "the", "rent"}}, {terms: []string{"car"}}}},
+		{q: `"unclosed phrase`, want: []searchClause{{terms: []string{"unclosed", "phrase"}}}},
+		{q: `"" * -`},
+	}
+	for _, tt := range tests {
+		if got := parseSearchQuery(tt.q); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%q: got %+v, want %+v", tt.q, got, tt.want)
+		}
+	}
+}
+
+func TestRemindersSearch(t *testing.T) {
+	rs := newTestReminders(NewFakeClock(time.Date(2030, 1, 1, 9, 0, 0, 0, time.UTC)))
+	create := func(title, message string) int {
+		reminder, err := rs.Create(ReminderCreateBody{Title: title, Message: message, Duration: time.Hour, RetryPeriod: time.Minute})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return reminder.ID
+	}
+	rent := create("Pay rent", "transfer the rent to the landlord")
+	landlord := create("Call landlord", "ask about the rent increase")
+	car := create("Rent a car", "for the trip")
+	plants := create("Water the", "plants")
+
+	search := func(q string) []int {
+		t.Helper()
+		results, err := rs.Search(q, 0)
+		if err != nil {
+			t.Fatalf("%q: %v", q, err)
+		}
+		ids := []int{}
+		for _, result := range results {
+			ids = append(ids, result.Reminder.ID)
+		}
+		return ids
+	}
+	tests := []struct {
+		q    string
+		want []int
+	}{
+		// the title matches rank first
+		{q: "rent", want: []int{rent, car, landlord}},
+		{q: "landlord", want: []int{landlord, rent}},
+		{q: "rent trip", want: []int{car}},
+		{q: "rent holiday", want: []int{}},
+		{q: `"the rent"`, want: []int{rent, landlord}},
+		{q: `"rent the"`, want: []int{}},
+		{q: `"the rent" increase`, want: []int{landlord}},
+		// phrases do not span the title and the message
+		{q: `"the plants"`, want: []int{}},
+		{q: "land*", want: []int{landlord, rent}},
+		{q: "ca*", want: []int{landlord, car}},
+		{q: "ca", want: []int{}},
+		{q: "xyz*", want: []int{}},
+	}
+	for _, tt := range tests {
+		if got := search(tt.q); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%q: got %v, want %v", tt.q, got, tt.want)
+		}
+	}
+	if _, err := rs.Search(" * ", 0); err == nil {
+		t.Error("got an empty query accepted, want an error")
+	}
+	if _, err := rs.Search("rent", maxListLimit+1); err == nil {
+		t.Error("got a limit above the maximum accepted, want an error")
+	}
+	if results, err := rs.Search("rent", 1); err != nil || len(results) != 1 {
+		t.Errorf("got %d results (%v), want the limit to apply", len(results), err)
+	}
+
+	// edited reminders are indexed again
+	if _, err := rs.Edit(ReminderEditBody{ID: car, Title: "Book a car", Message: "for the holiday"}); err != nil {
+		t.Fatal(err)
+	}
+	if got := search("trip"); len(got) != 0 {
+		t.Errorf("got %v for the old message, want no match", got)
+	}
+	if got := search("holiday car*"); !reflect.DeepEqual(got, []int{car}) {
+		t.Errorf("got %v for the new message, want %v", got, []int{car})
+	}
+	if got := search("rent"); !reflect.DeepEqual(got, []int{rent, landlord}) {
+		t.Errorf("got %v for the old title, want %v", got, []int{rent, landlord})
+	}
+
+	// deleted reminders are removed from the index
+	if err := rs.Delete([]int{rent, plants}); err != nil {
+		t.Fatal(err)
+	}
+	if got := search("rent"); !reflect.DeepEqual(got, []int{landlord}) {
+		t.Errorf("got %v after the delete, want %v", got, []int{landlord})
+	}
+
+	rs.mu.RLock()
+	defer rs.mu.RUnlock()
+	if len(rs.search.docs) != 2 {
+		t.Fatalf("got %d indexed reminders, want 2", len(rs.search.docs))
+	}
+	if !sort.StringsAreSorted(rs.search.terms) || len(rs.search.terms) != len(rs.search.postings) {
+		t.Fatalf("got terms %v out of sync with the postings", rs.search.terms)
+	}
+	for _, term := range []string{"trip", "transfer", "pay", "water", "plants"} {
+		if _, ok := rs.search.postings[term]; ok {
+			t.Errorf("term %q of an edited or deleted reminder is still indexed", term)
+		}
+	}
+}
@@ -0,0 +1,60 @@
+package services
+
+import (
+	"encoding/json"
+	"github.com/muhtutorials/reminders_cli/server/models"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+// SinkChannel writes reminder deliveries as JSON lines to a file or stdout,
+// it lets headless servers run without any notifier and every written delivery completes its reminder
+type SinkChannel struct {
+	mu   *sync.Mutex
+	path string
+}
+
+// NewSinkChannel creates a sink appending to the file at path, "-" writes to stdout
+func NewSinkChannel(path string) SinkChannel {
+	return SinkChannel{
+		mu:   &sync.Mutex{},
+		path: path,
+	}
+}
+
+// Notify appends a reminder delivery to the sink
+func (s SinkChannel) Notify(delivery models.Delivery, reminder models.Reminder) (NotificationResponse, error) {
+	line, err := json.Marshal(struct {
+		DeliveryID string          `json:"delivery_id"`
+		SentAt     *time.Time      `json:"sent_at,omitempty"`
+		Reminder   models.Reminder `json:"reminder"`
+	}{
+		DeliveryID: delivery.ID,
+		SentAt:     delivery.SentAt,
+		Reminder:   reminder,
+	})
+	if err != nil {
+		e := models.WrapError("could not marshal json", err)
+		return NotificationResponse{}, e
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var w io.Writer = os.Stdout
+	if s.path != "-" {
+		f, err := os.OpenFile(s.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+		if err != nil {
+			e := models.WrapError("could not open sink file", err)
+			return NotificationResponse{}, e
+		}
+		defer f.Close()
+		w = f
+	}
+	if _, err := w.Write(append(line, '\n')); err != nil {
+		e := models.WrapError("could not write to sink", err)
+		return NotificationResponse{}, e
+	}
+	return NotificationResponse{result: NotificationResult{Action: ActionDelivered}}, nil
+}
@@ -0,0 +1,134 @@
+package services
+
+import (
+	"fmt"
+	"github.com/muhtutorials/reminders_cli/server/models"
+	"sort"
+	"strings"
+)
+
+const (
+	// TagMatchAny matches the reminders having at least one of the filter tags
+	TagMatchAny = "any"
+	// TagMatchAll matches the reminders having all the filter tags
+	TagMatchAll = "all"
+	// TagMatchNone matches the reminders having none of the filter tags
+	TagMatchNone = "none"
+
+	maxTagLength = 64
+)
+
+// TagFilter represents a filter of reminders by their tags, Match is TagMatchAny when empty
+// and an empty filter matches all the reminders
+type TagFilter struct {
+	Tags  []string
+	Match string
+}
+
+// TagCount represents the number of reminders using a tag
+type TagCount struct {
+	Tag   string `json:"tag"`
+	Count int    `json:"count"`
+}
+
+// Tags retrieves the tags used by the reminders, the most used first
+func (rs *Reminders) Tags() []TagCount {
+	counts := map[string]int{}
+	rs.mu.RLock()
+	for id := range rs.state.All {
+		_, reminder := rs.state.All.flatten(id)
+		for _, tag := range reminder.Tags {
+			counts[tag]++
+		}
+	}
+	rs.mu.RUnlock()
+
+	tags := make([]TagCount, 0, len(counts))
+	for tag, count := range counts {
+		tags = append(tags, TagCount{Tag: tag, Count: count})
+	}
+	sort.Slice(tags, func(i, j int) bool {
+		if tags[i].Count != tags[j].Count {
+			return tags[i].Count > tags[j].Count
+		}
+		return tags[i].Tag < tags[j].Tag
+	})
+	return tags
+}
+
+// validate validates the filter and normalizes its tags
+func (f *TagFilter) validate() error {
+	switch f.Match {
+	case "":
+		f.Match = TagMatchAny
+	case TagMatchAny, TagMatchAll, TagMatchNone:
+	default:
+		return models.DataValidationError{
+			Message: fmt.Sprintf("invalid tag match '%s', expected any, all or none", f.Match),
+		}
+	}
+	tags, err := normalizeTags(f.Tags)
+	if err != nil {
+		return err
+	}
+	f.Tags = tags
+	return nil
+}
+
+// matches checks whether the tags of a reminder satisfy the filter
+func (f TagFilter) matches(tags []string) bool {
+	if len(f.Tags) == 0 {
+		return true
+	}
+	found := 0
+	for _, tag := range f.Tags {
+		if hasTag(tags, tag) {
+			found++
+		}
+	}
+	switch f.Match {
+	case TagMatchAll:
+		return found == len(f.Tags)
+	case TagMatchNone:
+		return found == 0
+	default:
+		return found > 0
+	}
+}
+
+// hasTag checks whether a tag is in the sorted tags
+func hasTag(tags []string, tag string) bool {
+	i := sort.SearchStrings(tags, tag)
+	return i < len(tags) && tags[i] == tag
+}
+
+// normalizeTags lowercases, sorts and deduplicates tags,
+// a tag can only contain letters, digits and the "-", "_", ".", ":" and "/" characters
+func normalizeTags(tags []string) ([]string, error) {
+	normalized := make([]string, 0, len(tags))
+	seen := map[string]bool{}
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag == "" || len(tag) > maxTagLength || strings.IndexFunc(tag, invalidTagRune) >= 0 {
+			return nil, models.DataValidationError{
+				Message: fmt.Sprintf("invalid tag '%s', expected up to %d letters, digits, '-', '_', '.', ':' or '/'", tag, maxTagLength),
+			}
+		}
+		if !seen[tag] {
+			seen[tag] = true
+			normalized = append(normalized, tag)
+		}
+	}
+	sort.Strings(normalized)
+	return normalized, nil
+}
+
+func invalidTagRune(r rune) bool {
+	switch {
+	case r >= 'a' && r <= 'z', r >= '0' && r <= '9':
+		return false
+	case strings.ContainsRune("-_.:/", r):
+		return false
+	}
+	return true
+}
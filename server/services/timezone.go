@@ -0,0 +1,53 @@
+package services
+
+import (
+	"fmt"
+	"github.com/muhtutorials/reminders_cli/server/models"
+	"time"
+)
+
+// TimeZoneLocal resets the time zone of an edited reminder to the server time zone
+const TimeZoneLocal = "Local"
+
+// loadTimeZone loads an IANA time zone, an empty name or TimeZoneLocal is the server time zone
+func loadTimeZone(name string) (*time.Location, error) {
+	if name == "" || name == TimeZoneLocal {
+		return time.Local, nil
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, models.DataValidationError{
+			Message: fmt.Sprintf("unknown time zone '%s'", name),
+		}
+	}
+	return loc, nil
+}
+
+// timeZoneName retrieves the name a time zone is stored with, the server time zone is stored as an empty name
+func timeZoneName(loc *time.Location) string {
+	if loc == time.Local {
+		return ""
+	}
+	return loc.String()
+}
+
+// reminderLocation retrieves the time zone the due time and the recurrence of a reminder are evaluated in
+func reminderLocation(reminder models.Reminder) *time.Location {
+	loc, err := loadTimeZone(reminder.TimeZone)
+	if err != nil {
+		return time.Local
+	}
+	return loc
+}
+
+// localize expresses the due time and the current occurrence of a reminder in its time zone
+func localize(reminder models.Reminder) models.Reminder {
+	loc := reminderLocation(reminder)
+	reminder.DueAt = reminder.DueAt.In(loc)
+	if reminder.Recurrence != nil {
+		recurrence := *reminder.Recurrence
+		recurrence.Current = recurrence.Current.In(loc)
+		reminder.Recurrence = &recurrence
+	}
+	return reminder
+}
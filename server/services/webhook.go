@@ -0,0 +1,30 @@
+package services
+
+import (
+	"github.com/muhtutorials/reminders_cli/server/models"
+	"net/http"
+	"time"
+)
+
+// WebhookChannel posts reminder deliveries as JSON to an arbitrary URL,
+// receivers may respond with the user action, call it back later (202) or just acknowledge the delivery
+type WebhookChannel struct {
+	url         string
+	callbackURL string
+	client      *http.Client
+}
+
+func NewWebhookChannel(url, callbackURL string) WebhookChannel {
+	return WebhookChannel{
+		url:         url,
+		callbackURL: callbackURL,
+		client: &http.Client{
+			Timeout: 20 * time.Second,
+		},
+	}
+}
+
+// Notify posts a reminder delivery to the webhook URL, an empty 2xx response means the reminder was delivered
+func (w WebhookChannel) Notify(delivery models.Delivery, reminder models.Reminder) (NotificationResponse, error) {
+	return postNotification(w.client, w.url, w.callbackURL, delivery, reminder, ActionDelivered)
+}
@@ -0,0 +1,392 @@
+package services
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"github.com/muhtutorials/reminders_cli/server/models"
+	"log"
+	"net/http"
+	"net/url"
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	EventReminderCreated   = "reminder.created"
+	EventReminderEdited    = "reminder.edited"
+	EventReminderFired     = "reminder.fired"
+	EventReminderSnoozed   = "reminder.snoozed"
+	EventReminderCompleted = "reminder.completed"
+	EventReminderCancelled = "reminder.cancelled"
+	EventReminderReopened  = "reminder.reopened"
+	EventReminderFailed    = "reminder.failed"
+	EventReminderDeleted   = "reminder.deleted"
+
+	// EventAll subscribes a webhook to all the events
+	EventAll = "*"
+
+	WebhookEventHeader     = "X-Reminders-Event"
+	WebhookDeliveryHeader  = "X-Reminders-Delivery"
+	WebhookSignatureHeader = "X-Reminders-Signature"
+
+	// maxWebhookDeliveries bounds the delivery log kept for every subscription
+	maxWebhookDeliveries = 50
+	maxWebhookBackoff    = 10 * time.Minute
+)
+
+var events = map[string]bool{
+	EventReminderCreated:   true,
+	EventReminderEdited:    true,
+	EventReminderFired:     true,
+	EventReminderSnoozed:   true,
+	EventReminderCompleted: true,
+	EventReminderCancelled: true,
+	EventReminderReopened:  true,
+	EventReminderFailed:    true,
+	EventReminderDeleted:   true,
+}
+
+// EventPublisher publishes the reminder lifecycle events
+type EventPublisher interface {
+	Publish(event string, reminder models.Reminder)
+}
+
+type WebhookRepository interface {
+	Load() ([]models.Webhook, error)
+	Save(webhooks []models.Webhook) error
+}
+
+// webhookEvent represents the body posted to the webhook subscriptions
+type webhookEvent struct {
+	ID        string          `json:"id"`
+	Event     string          `json:"event"`
+	CreatedAt time.Time       `json:"created_at"`
+	Reminder  models.Reminder `json:"reminder"`
+}
+
+// Webhooks represents the webhook subscriptions service,
+// every event is posted to the matching subscriptions signed with HMAC-SHA256 of the subscription secret
+// and failed deliveries are retried with exponential backoff
+type Webhooks struct {
+	mu       sync.Mutex
+	repo     WebhookRepository
+	webhooks map[string]*models.Webhook
+	client   *http.Client
+	attempts int
+	backoff  time.Duration
+	clock    Clock
+	done     chan struct{}
+}
+
+// NewWebhooks creates the webhooks service delivering every event at most attempts times,
+// the delay before a retry starts at backoff and doubles on every attempt
+func NewWebhooks(repo WebhookRepository, attempts int, backoff time.Duration, clock Clock) *Webhooks {
+	return &Webhooks{
+		repo:     repo,
+		webhooks: map[string]*models.Webhook{},
+		client: &http.Client{
+			Timeout: 10 * time.Second,
+		},
+		attempts: attempts,
+		backoff:  backoff,
+		clock:    clock,
+		done:     make(chan struct{}),
+	}
+}
+
+// Populate loads the webhook subscriptions, deliveries interrupted by a shutdown are marked as failed
+func (w *Webhooks) Populate() error {
+	webhooks, err := w.repo.Load()
+	if err != nil {
+		return err
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for i := range webhooks {
+		webhook := webhooks[i]
+		for j, delivery := range webhook.Deliveries {
+			if delivery.Status == models.WebhookDeliveryPending {
+				webhook.Deliveries[j].Status = models.WebhookDeliveryFailed
+				webhook.Deliveries[j].Error = "interrupted by a shutdown"
+			}
+		}
+		w.webhooks[webhook.ID] = &webhook
+	}
+	return nil
+}
+
+// WebhookCreateBody represents the model for creating a webhook subscription,
+// a random secret is generated when Secret is empty
+type WebhookCreateBody struct {
+	URL    string
+	Events []string
+	Secret string
+}
+
+// Create registers a new webhook subscription, it is the only response containing the secret
+func (w *Webhooks) Create(body WebhookCreateBody) (models.Webhook, error) {
+	u, err := url.Parse(body.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		err := models.DataValidationError{
+			Message: "url must be an absolute http or https URL",
+		}
+		return models.Webhook{}, err
+	}
+	var subscribed []string
+	for _, event := range body.Events {
+		if event == EventAll {
+			subscribed = nil
+			break
+		}
+		if !events[event] {
+			err := models.DataValidationError{
+				Message: fmt.Sprintf("unknown event '%s'", event),
+			}
+			return models.Webhook{}, err
+		}
+		subscribed = append(subscribed, event)
+	}
+	webhook := models.Webhook{
+		ID:        newDeliveryID(),
+		URL:       body.URL,
+		Events:    subscribed,
+		Secret:    body.Secret,
+		CreatedAt: w.clock.Now(),
+	}
+	if webhook.Secret == "" {
+		webhook.Secret = newDeliveryID() + newDeliveryID()
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.webhooks[webhook.ID] = &webhook
+	if err := w.save(); err != nil {
+		delete(w.webhooks, webhook.ID)
+		return models.Webhook{}, err
+	}
+	return webhook, nil
+}
+
+// List lists the webhook subscriptions without their secrets and delivery logs
+func (w *Webhooks) List() []models.Webhook {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	webhooks := make([]models.Webhook, 0, len(w.webhooks))
+	for _, webhook := range w.sorted() {
+		webhook.Secret = ""
+		webhook.Deliveries = nil
+		webhooks = append(webhooks, webhook)
+	}
+	return webhooks
+}
+
+// Fetch fetches a webhook subscription with its delivery log but without its secret
+func (w *Webhooks) Fetch(id string) (models.Webhook, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	webhook, ok := w.webhooks[id]
+	if !ok {
+		return models.Webhook{}, webhookNotFound(id)
+	}
+	fetched := *webhook
+	fetched.Secret = ""
+	fetched.Deliveries = append([]models.WebhookDelivery(nil), webhook.Deliveries...)
+	return fetched, nil
+}
+
+// Delete deletes a webhook subscription, its pending deliveries are abandoned
+func (w *Webhooks) Delete(id string) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	webhook, ok := w.webhooks[id]
+	if !ok {
+		return webhookNotFound(id)
+	}
+	delete(w.webhooks, id)
+	if err := w.save(); err != nil {
+		w.webhooks[id] = webhook
+		return err
+	}
+	return nil
+}
+
+// Publish posts an event about the reminder to all the subscriptions of the event without blocking
+func (w *Webhooks) Publish(event string, reminder models.Reminder) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for _, webhook := range w.webhooks {
+		if !subscribed(*webhook, event) {
+			continue
+		}
+		now := w.clock.Now()
+		delivery := models.WebhookDelivery{
+			ID:         newDeliveryID(),
+			Event:      event,
+			ReminderID: reminder.ID,
+			Status:     models.WebhookDeliveryPending,
+			CreatedAt:  now,
+		}
+		body, err := json.Marshal(webhookEvent{
+			ID:        delivery.ID,
+			Event:     event,
+			CreatedAt: now,
+			Reminder:  reminder,
+		})
+		if err != nil {
+			log.Printf("could not marshal webhook event: %v", err)
+			continue
+		}
+		webhook.Deliveries = append(webhook.Deliveries, delivery)
+		if n := len(webhook.Deliveries); n > maxWebhookDeliveries {
+			webhook.Deliveries = webhook.Deliveries[n-maxWebhookDeliveries:]
+		}
+		go w.deliver(*webhook, delivery, body)
+	}
+}
+
+// Stop abandons the retries of the pending deliveries and saves the delivery logs
+func (w *Webhooks) Stop() error {
+	close(w.done)
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if err := w.save(); err != nil {
+		return err
+	}
+	log.Println("webhooks stopped")
+	return nil
+}
+
+// deliver posts an event body to a subscription until it succeeds or runs out of attempts
+func (w *Webhooks) deliver(webhook models.Webhook, delivery models.WebhookDelivery, body []byte) {
+	delay := w.backoff
+	for {
+		delivery.Attempts++
+		delivery.LastAttemptAt = w.clock.Now()
+		code, err := w.post(webhook, delivery, body)
+		delivery.StatusCode, delivery.Error = code, ""
+		retryable := true
+		switch {
+		case err != nil:
+			delivery.Error = err.Error()
+		case code >= 200 && code < 300:
+			delivery.Status = models.WebhookDeliverySucceeded
+			w.record(webhook.ID, delivery, true)
+			return
+		default:
+			delivery.Error = fmt.Sprintf("responded with status code %d", code)
+			// client errors other than rate limiting would fail again
+			retryable = code >= 500 || code == http.StatusTooManyRequests
+		}
+		if !retryable || delivery.Attempts >= w.attempts {
+			log.Printf("webhook %s gave up %s delivery %s: %s", webhook.ID, delivery.Event, delivery.ID, delivery.Error)
+			delivery.Status = models.WebhookDeliveryFailed
+			w.record(webhook.ID, delivery, true)
+			return
+		}
+		if !w.record(webhook.ID, delivery, false) {
+			// the subscription was deleted
+			return
+		}
+		timer := w.clock.NewTimer(delay)
+		select {
+		case <-timer.C():
+		case <-w.done:
+			timer.Stop()
+			return
+		}
+		if delay *= 2; delay > maxWebhookBackoff {
+			delay = maxWebhookBackoff
+		}
+	}
+}
+
+// post signs and posts an event body to a subscription
+func (w *Webhooks) post(webhook models.Webhook, delivery models.WebhookDelivery, body []byte) (int, error) {
+	req, err := http.NewRequest(http.MethodPost, webhook.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(WebhookEventHeader, delivery.Event)
+	req.Header.Set(WebhookDeliveryHeader, delivery.ID)
+	req.Header.Set(WebhookSignatureHeader, SignWebhook(webhook.Secret, body))
+	res, err := w.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	res.Body.Close()
+	return res.StatusCode, nil
+}
+
+// record updates a delivery in the log of its subscription and saves the logs once the delivery is over,
+// false is returned when the subscription does not exist anymore
+func (w *Webhooks) record(webhookID string, delivery models.WebhookDelivery, over bool) bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	webhook, ok := w.webhooks[webhookID]
+	if !ok {
+		return false
+	}
+	for i := range webhook.Deliveries {
+		if webhook.Deliveries[i].ID == delivery.ID {
+			webhook.Deliveries[i] = delivery
+		}
+	}
+	if over {
+		if err := w.save(); err != nil {
+			log.Printf("could not save webhooks: %v", err)
+		}
+	}
+	return true
+}
+
+// save persists the subscriptions, it must be called with mu held
+func (w *Webhooks) save() error {
+	return w.repo.Save(w.sorted())
+}
+
+// sorted retrieves copies of the subscriptions in creation order, it must be called with mu held
+func (w *Webhooks) sorted() []models.Webhook {
+	webhooks := make([]models.Webhook, 0, len(w.webhooks))
+	for _, webhook := range w.webhooks {
+		webhooks = append(webhooks, *webhook)
+	}
+	sort.Slice(webhooks, func(i, j int) bool {
+		if webhooks[i].CreatedAt.Equal(webhooks[j].CreatedAt) {
+			return webhooks[i].ID < webhooks[j].ID
+		}
+		return webhooks[i].CreatedAt.Before(webhooks[j].CreatedAt)
+	})
+	return webhooks
+}
+
+// SignWebhook computes the signature header value of a webhook body: "sha256=" followed by
+// the hex encoded HMAC-SHA256 of the body keyed with the subscription secret
+func SignWebhook(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func subscribed(webhook models.Webhook, event string) bool {
+	if len(webhook.Events) == 0 {
+		return true
+	}
+	for _, e := range webhook.Events {
+		if e == event {
+			return true
+		}
+	}
+	return false
+}
+
+func webhookNotFound(id string) error {
+	return models.NotFoundError{
+		Message: fmt.Sprintf("could not find webhook with id: %s", id),
+	}
+}
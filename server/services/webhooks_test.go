@@ -0,0 +1,129 @@
+package services
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"github.com/muhtutorials/reminders_cli/server/models"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+// memoryWebhooks represents an in memory webhook repository
+type memoryWebhooks struct {
+	mu       sync.Mutex
+	webhooks []models.Webhook
+}
+
+func (r *memoryWebhooks) Load() ([]models.Webhook, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.webhooks, nil
+}
+
+func (r *memoryWebhooks) Save(webhooks []models.Webhook) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.webhooks = webhooks
+	return nil
+}
+
+// waitDelivery waits until the only delivery of a webhook is over
+func waitDelivery(t *testing.T, w *Webhooks, id string) models.WebhookDelivery {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		webhook, err := w.Fetch(id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(webhook.Deliveries) == 1 && webhook.Deliveries[0].Status != models.WebhookDeliveryPending {
+			return webhook.Deliveries[0]
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	t.Fatal("webhook delivery did not finish")
+	return models.WebhookDelivery{}
+}
+
+func TestWebhooksSignAndRetryDeliveries(t *testing.T) {
+	const secret = "s3cret"
+	var (
+		mu       sync.Mutex
+		attempts int
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		mac := hmac.New(sha256.New, []byte(secret))
+		mac.Write(body)
+		if want := "sha256=" + hex.EncodeToString(mac.Sum(nil)); r.Header.Get(WebhookSignatureHeader) != want {
+			t.Errorf("got signature %q, want %q", r.Header.Get(WebhookSignatureHeader), want)
+		}
+		if got := r.Header.Get(WebhookEventHeader); got != EventReminderCreated {
+			t.Errorf("got event header %q, want %q", got, EventReminderCreated)
+		}
+		var event webhookEvent
+		if err := json.Unmarshal(body, &event); err != nil || event.Reminder.ID != 7 {
+			t.Errorf("got body %s, want the created reminder", body)
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		attempts++
+		if attempts < 3 {
+			rw.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer srv.Close()
+
+	w := NewWebhooks(&memoryWebhooks{}, 5, time.Millisecond, NewRealClock())
+	webhook, err := w.Create(WebhookCreateBody{URL: srv.URL, Events: []string{EventReminderCreated}, Secret: secret})
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.Publish(EventReminderEdited, models.Reminder{ID: 7})
+	w.Publish(EventReminderCreated, models.Reminder{ID: 7})
+
+	delivery := waitDelivery(t, w, webhook.ID)
+	if delivery.Status != models.WebhookDeliverySucceeded {
+		t.Fatalf("got delivery status %s, want succeeded: %s", delivery.Status, delivery.Error)
+	}
+	if delivery.Attempts != 3 {
+		t.Fatalf("got %d attempts, want 3", delivery.Attempts)
+	}
+}
+
+func TestWebhooksGiveUpAfterAttempts(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		attempts int
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		attempts++
+		mu.Unlock()
+		rw.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer srv.Close()
+
+	w := NewWebhooks(&memoryWebhooks{}, 2, time.Millisecond, NewRealClock())
+	webhook, err := w.Create(WebhookCreateBody{URL: srv.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.Publish(EventReminderFired, models.Reminder{ID: 1})
+
+	delivery := waitDelivery(t, w, webhook.ID)
+	if delivery.Status != models.WebhookDeliveryFailed || delivery.Attempts != 2 {
+		t.Fatalf("got delivery %s after %d attempts, want failed after 2", delivery.Status, delivery.Attempts)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if attempts != 2 {
+		t.Fatalf("endpoint received %d attempts, want 2", attempts)
+	}
+}
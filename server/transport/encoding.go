@@ -12,6 +12,7 @@ const (
 	dataValidationErrType   = "data_validation_error"
 	formatValidationErrType = "format_validation_error"
 	invalidJSONErrType      = "invalid_json_error"
+	conflictErrType         = "conflict_error"
 	serviceErrType          = "service_error"
 )
 
@@ -54,6 +55,9 @@ func toHTTPError(err error) models.HTTPError {
 	case models.InvalidJSONError:
 		resErr.Code = http.StatusBadRequest
 		resErr.Type = invalidJSONErrType
+	case models.ConflictError:
+		resErr.Code = http.StatusConflict
+		resErr.Type = conflictErrType
 	default:
 		resErr.Code = http.StatusInternalServerError
 		resErr.Type = serviceErrType